@@ -0,0 +1,109 @@
+package mnemosyne
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// sessionCache is a bounded LRU cache of sessions, kept by the client.
+// Entry is valid until cache lifetime passes or session expires, whichever comes first.
+// Sessions are copied when stored and retrieved, so callers cannot modify cached ones.
+type sessionCache struct {
+	sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type sessionCacheEntry struct {
+	key     string
+	session *Session
+	validTo time.Time
+}
+
+func newSessionCache(size int, ttl time.Duration) *sessionCache {
+	return &sessionCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element, size),
+		lru:     list.New(),
+	}
+}
+
+func (sc *sessionCache) get(key string) (*Session, bool) {
+	sc.Lock()
+	defer sc.Unlock()
+
+	el, ok := sc.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*sessionCacheEntry)
+	if !entry.validTo.After(time.Now()) {
+		sc.remove(el)
+		return nil, false
+	}
+
+	sc.lru.MoveToFront(el)
+
+	return proto.Clone(entry.session).(*Session), true
+}
+
+func (sc *sessionCache) set(key string, ses *Session) {
+	if ses == nil {
+		return
+	}
+
+	validTo := time.Now().Add(sc.ttl)
+	if ses.ExpireAt != nil && ses.ExpireAt.Time().Before(validTo) {
+		validTo = ses.ExpireAt.Time()
+	}
+
+	sc.Lock()
+	defer sc.Unlock()
+
+	if el, ok := sc.entries[key]; ok {
+		sc.remove(el)
+	}
+
+	sc.entries[key] = sc.lru.PushFront(&sessionCacheEntry{
+		key:     key,
+		session: proto.Clone(ses).(*Session),
+		validTo: validTo,
+	})
+
+	for sc.lru.Len() > sc.size {
+		sc.remove(sc.lru.Back())
+	}
+}
+
+func (sc *sessionCache) del(key string) {
+	sc.Lock()
+	defer sc.Unlock()
+
+	if el, ok := sc.entries[key]; ok {
+		sc.remove(el)
+	}
+}
+
+// delSubject removes every session of given subject, except the one identified by given key.
+func (sc *sessionCache) delSubject(subjectID, except string) {
+	sc.Lock()
+	defer sc.Unlock()
+
+	for key, el := range sc.entries {
+		if key != except && el.Value.(*sessionCacheEntry).session.SubjectId == subjectID {
+			sc.remove(el)
+		}
+	}
+}
+
+func (sc *sessionCache) remove(el *list.Element) {
+	delete(sc.entries, el.Value.(*sessionCacheEntry).key)
+	sc.lru.Remove(el)
+}
@@ -0,0 +1,71 @@
+package mnemosyne
+
+import (
+	"testing"
+	"time"
+
+	"github.com/piotrkowalczuk/protot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionCache(t *testing.T) {
+	sc := newSessionCache(2, time.Minute)
+	expireAt := protot.TimeToTimestamp(time.Now().Add(time.Hour))
+
+	sc.set("1", &Session{SubjectId: "subject-1", ExpireAt: expireAt})
+	sc.set("2", &Session{SubjectId: "subject-2", ExpireAt: expireAt})
+	_, ok := sc.get("1")
+	assert.True(t, ok)
+
+	// Least recently used session is evicted.
+	sc.set("3", &Session{SubjectId: "subject-1", ExpireAt: expireAt})
+	_, ok = sc.get("2")
+	assert.False(t, ok)
+
+	sc.delSubject("subject-1", "3")
+	_, ok = sc.get("1")
+	assert.False(t, ok)
+	ses, ok := sc.get("3")
+	if assert.True(t, ok) {
+		assert.Equal(t, "subject-1", ses.SubjectId)
+	}
+
+	sc.del("3")
+	_, ok = sc.get("3")
+	assert.False(t, ok)
+}
+
+func TestSessionCache_expiration(t *testing.T) {
+	sc := newSessionCache(10, time.Millisecond)
+	sc.set("ttl", &Session{ExpireAt: protot.TimeToTimestamp(time.Now().Add(time.Hour))})
+
+	sc2 := newSessionCache(10, time.Hour)
+	sc2.set("expired", &Session{ExpireAt: protot.TimeToTimestamp(time.Now().Add(-time.Second))})
+
+	time.Sleep(2 * time.Millisecond)
+
+	_, ok := sc.get("ttl")
+	assert.False(t, ok)
+	_, ok = sc2.get("expired")
+	assert.False(t, ok)
+	assert.Len(t, sc2.entries, 0)
+}
+
+func TestSessionCache_isolation(t *testing.T) {
+	sc := newSessionCache(10, time.Minute)
+	ses := &Session{SubjectId: "subject", Bag: map[string]string{"key": "value"}, ExpireAt: protot.TimeToTimestamp(time.Now().Add(time.Hour))}
+
+	sc.set("1", ses)
+	ses.Bag["key"] = "modified by caller that stored it"
+
+	got, ok := sc.get("1")
+	if assert.True(t, ok) {
+		assert.Equal(t, "value", got.Bag["key"])
+		got.Bag["key"] = "modified by caller that retrieved it"
+	}
+
+	got, ok = sc.get("1")
+	if assert.True(t, ok) {
+		assert.Equal(t, "value", got.Bag["key"])
+	}
+}
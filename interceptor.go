@@ -0,0 +1,117 @@
+package mnemosyne
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+type sessionContextKey struct{}
+
+// NewSessionContext returns a new Context that carries Session value.
+func NewSessionContext(ctx context.Context, ses *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, ses)
+}
+
+// SessionFromContext returns the Session value stored in context, if any.
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	ses, ok := ctx.Value(sessionContextKey{}).(*Session)
+
+	return ses, ok
+}
+
+// UnaryServerInterceptor puts session token taken from incoming metadata into the context (see TokenFromContext).
+// If m is not nil, session is retrieved as well (see SessionFromContext). Call without valid session is passed to the handler anyway.
+func UnaryServerInterceptor(m Mnemosyne) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := incoming(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor works like UnaryServerInterceptor but for streaming calls.
+func StreamServerInterceptor(m Mnemosyne) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := incoming(stream.Context(), m)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// UnaryClientInterceptor forwards session token from the context (see NewTokenContext) to downstream calls,
+// unless metadata already carries one.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(forward(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor works like UnaryClientInterceptor but for streaming calls.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(forward(ctx), desc, cc, method, opts...)
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream interface.
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+func incoming(ctx context.Context, m Mnemosyne) (context.Context, error) {
+	md, ok := metadata.FromContext(ctx)
+	if !ok || len(md[TokenMetadataKey]) == 0 {
+		return ctx, nil
+	}
+
+	token := DecodeToken([]byte(md[TokenMetadataKey][0]))
+	if token.IsEmpty() {
+		return ctx, nil
+	}
+
+	ctx = NewTokenContext(ctx, token)
+	if m == nil {
+		return ctx, nil
+	}
+
+	ses, err := m.Get(ctx, token)
+	switch {
+	case err == nil:
+		return NewSessionContext(ctx, ses), nil
+	case grpc.Code(err) == codes.NotFound:
+		return ctx, nil
+	default:
+		return nil, err
+	}
+}
+
+func forward(ctx context.Context) context.Context {
+	md, _ := metadata.FromContext(ctx)
+
+	return withToken(ctx, md.Copy())
+}
+
+// withToken attaches session token from the context to given metadata, unless it already carries one.
+func withToken(ctx context.Context, md metadata.MD) context.Context {
+	if token, ok := TokenFromContext(ctx); ok && len(md[TokenMetadataKey]) == 0 {
+		md[TokenMetadataKey] = []string{token.Encode()}
+	}
+	if md.Len() == 0 {
+		return ctx
+	}
+
+	return metadata.NewContext(ctx, md)
+}
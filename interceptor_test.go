@@ -0,0 +1,90 @@
+package mnemosyne
+
+import (
+	"testing"
+	"time"
+
+	"github.com/piotrkowalczuk/protot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	token := NewToken([]byte("0000000key"), []byte("hash"))
+	stub := &rpcClientStub{
+		session: &Session{Token: &token, ExpireAt: protot.TimeToTimestamp(time.Now().Add(time.Hour))},
+	}
+	ctx := metadata.NewContext(context.Background(), metadata.Pairs(TokenMetadataKey, token.Encode()))
+
+	data := map[string]struct {
+		mnemosyne Mnemosyne
+		ctx       context.Context
+		token     bool
+		session   bool
+	}{
+		"without-metadata": {
+			ctx: context.Background(),
+		},
+		"token-only": {
+			ctx:   ctx,
+			token: true,
+		},
+		"session": {
+			mnemosyne: &mnemosyne{client: stub},
+			ctx:       ctx,
+			token:     true,
+			session:   true,
+		},
+	}
+
+	for hint, d := range data {
+		_, err := UnaryServerInterceptor(d.mnemosyne)(d.ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			tkn, ok := TokenFromContext(ctx)
+			if assert.Equal(t, d.token, ok, hint) && ok {
+				assert.Equal(t, token.Encode(), tkn.Encode(), hint)
+			}
+			ses, ok := SessionFromContext(ctx)
+			if assert.Equal(t, d.session, ok, hint) && ok {
+				assert.Equal(t, stub.session, ses, hint)
+			}
+
+			return nil, nil
+		})
+		require.NoError(t, err, hint)
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	token := NewToken([]byte("0000000key"), []byte("hash"))
+	other := NewToken([]byte("0000000key"), []byte("other"))
+
+	data := map[string]struct {
+		ctx      context.Context
+		expected []string
+	}{
+		"without-token": {
+			ctx: context.Background(),
+		},
+		"token": {
+			ctx:      NewTokenContext(context.Background(), token),
+			expected: []string{token.Encode()},
+		},
+		"token-in-metadata": {
+			ctx:      metadata.NewContext(NewTokenContext(context.Background(), token), metadata.Pairs(TokenMetadataKey, other.Encode())),
+			expected: []string{other.Encode()},
+		},
+	}
+
+	for hint, d := range data {
+		err := UnaryClientInterceptor()(d.ctx, "/method", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromContext(ctx)
+			assert.Equal(t, d.expected, md[TokenMetadataKey], hint)
+
+			return nil
+		})
+		require.NoError(t, err, hint)
+	}
+}
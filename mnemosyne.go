@@ -1,9 +1,12 @@
 package mnemosyne
 
 import (
+	"time"
+
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
 const (
@@ -11,6 +14,8 @@ const (
 	TokenContextKey = "mnemosyne_token"
 	// TokenMetadataKey is used by Mnemosyne to retrieve session token from gRPC metadata object.
 	TokenMetadataKey = "mnemosyne_token"
+	// APIKeyMetadataKey is used by Mnemosyne to identify the caller if authorization is enabled, it can be set using MnemosyneOpts.Metadata.
+	APIKeyMetadataKey = "mnemosyne_api_key"
 )
 
 var (
@@ -20,6 +25,8 @@ var (
 	ErrMissingToken = grpc.Errorf(codes.InvalidArgument, "mnemosyne: missing token")
 	// ErrMissingSubjectID can be returned by start endpoint if subject was not provided.
 	ErrMissingSubjectID = grpc.Errorf(codes.InvalidArgument, "mnemosyne: missing subject id")
+	// ErrInvalidPageToken can be returned by list endpoint if page token is malformed.
+	ErrInvalidPageToken = grpc.Errorf(codes.InvalidArgument, "mnemosyne: invalid page token")
 )
 
 //// NewTokenContext returns a new Context that carries Token value.
@@ -40,47 +47,104 @@ type Mnemosyne interface {
 	Get(context.Context, Token) (*Session, error)
 	Exists(context.Context, Token) (bool, error)
 	Start(context.Context, string, map[string]string) (*Session, error)
+	StartWithTTL(context.Context, string, time.Duration, map[string]string) (*Session, error)
 	Abandon(context.Context, Token) error
+	AbandonAll(context.Context, string, *Token) (int64, error)
+	Touch(context.Context, Token) (*Session, error)
+	TouchWithTTL(context.Context, Token, time.Duration) (*Session, error)
 	SetValue(context.Context, Token, string, string) (map[string]string, error)
-	//	DeleteValue(context.Context, string) (*Session, error)
-	//	Clear(context.Context) error
+	DeleteValue(context.Context, Token, string) (*Session, error)
+	Clear(context.Context, Token) error
+	Watch(context.Context, string) (RPC_WatchClient, error)
 }
 
 type mnemosyne struct {
-	metadata []string
+	metadata metadata.MD
 	client   RPCClient
+	// cache is nil if caching is disabled.
+	cache *sessionCache
 }
 
 // MnemosyneOpts ...
 type MnemosyneOpts struct {
+	// Metadata is a list of key-value pairs attached to every call, odd number of elements causes panic.
 	Metadata []string
+	// CacheSize is a maximum number of sessions cached by the client, 0 disables cache.
+	CacheSize int
+	// CacheTTL is how long session can be served from the cache, it is never served after it expires.
+	// Changes made by other clients are not visible until then.
+	CacheTTL time.Duration
 }
 
 // New allocates new mnemosyne instance.
+// Connection secured by (mutual) TLS can be established using NewClientCredentials.
 func New(conn *grpc.ClientConn, options MnemosyneOpts) Mnemosyne {
-	return &mnemosyne{
-		client: NewRPCClient(conn),
+	m := &mnemosyne{
+		metadata: metadata.Pairs(options.Metadata...),
+		client:   NewRPCClient(conn),
+	}
+	if options.CacheSize > 0 && options.CacheTTL > 0 {
+		m.cache = newSessionCache(options.CacheSize, options.CacheTTL)
 	}
+
+	return m
 }
 
 // FromContext implements Mnemosyne interface.
+// Session token is taken from the context (see NewTokenContext) or from already attached metadata.
 func (m *mnemosyne) FromContext(ctx context.Context) (*Session, error) {
-	return m.client.Context(ctx, &Empty{})
+	ctx = m.outgoing(ctx)
+
+	var key string
+	if m.cache != nil {
+		if md, ok := metadata.FromContext(ctx); ok && len(md[TokenMetadataKey]) > 0 {
+			key = md[TokenMetadataKey][0]
+		}
+	}
+	if key != "" {
+		if ses, ok := m.cache.get(key); ok {
+			return ses, nil
+		}
+	}
+
+	ses, err := m.client.Context(ctx, &Empty{})
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		m.cache.set(key, ses)
+	}
+
+	return ses, nil
 }
 
 // Get implements Mnemosyne interface.
 func (m *mnemosyne) Get(ctx context.Context, token Token) (*Session, error) {
-	res, err := m.client.Get(ctx, &GetRequest{Token: &token})
+	if m.cache != nil {
+		if ses, ok := m.cache.get(token.Encode()); ok {
+			return ses, nil
+		}
+	}
+
+	res, err := m.client.Get(m.outgoing(ctx), &GetRequest{Token: &token})
 	if err != nil {
 		return nil, err
 	}
 
+	m.cached(token, res.Session)
+
 	return res.Session, nil
 }
 
 // Exists implements Mnemosyne interface.
 func (m *mnemosyne) Exists(ctx context.Context, token Token) (bool, error) {
-	res, err := m.client.Exists(ctx, &ExistsRequest{Token: &token})
+	if m.cache != nil {
+		if _, ok := m.cache.get(token.Encode()); ok {
+			return true, nil
+		}
+	}
+
+	res, err := m.client.Exists(m.outgoing(ctx), &ExistsRequest{Token: &token})
 
 	if err != nil {
 		return false, err
@@ -90,10 +154,18 @@ func (m *mnemosyne) Exists(ctx context.Context, token Token) (bool, error) {
 }
 
 // Create implements Mnemosyne interface.
+// Session lifetime is the server default.
 func (m *mnemosyne) Start(ctx context.Context, subjectID string, data map[string]string) (*Session, error) {
-	res, err := m.client.Start(ctx, &StartRequest{
+	return m.StartWithTTL(ctx, subjectID, 0, data)
+}
+
+// StartWithTTL implements Mnemosyne interface.
+// Lifetime is rounded up to whole seconds, 0 means server default. Server can reject lifetime above its limit.
+func (m *mnemosyne) StartWithTTL(ctx context.Context, subjectID string, ttl time.Duration, data map[string]string) (*Session, error) {
+	res, err := m.client.Start(m.outgoing(ctx), &StartRequest{
 		SubjectId: subjectID,
 		Bag:       data,
+		Ttl:       int64((ttl + time.Second - 1) / time.Second),
 	})
 	if err != nil {
 		return nil, err
@@ -104,14 +176,58 @@ func (m *mnemosyne) Start(ctx context.Context, subjectID string, data map[string
 
 // Abandon implements Mnemosyne interface.
 func (m *mnemosyne) Abandon(ctx context.Context, token Token) error {
-	_, err := m.client.Abandon(ctx, &AbandonRequest{Token: &token})
+	defer m.uncached(token)
+
+	_, err := m.client.Abandon(m.outgoing(ctx), &AbandonRequest{Token: &token})
 
 	return err
 }
 
+// AbandonAll implements Mnemosyne interface.
+// It abandons every session of given subject except the one identified by given token, if any.
+func (m *mnemosyne) AbandonAll(ctx context.Context, subjectID string, except *Token) (int64, error) {
+	defer m.uncachedSubject(subjectID, except)
+
+	res, err := m.client.AbandonAll(m.outgoing(ctx), &AbandonAllRequest{
+		SubjectId: subjectID,
+		Except:    except,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.Count, nil
+}
+
+// Touch implements Mnemosyne interface.
+// Session lifetime is extended by the server default.
+func (m *mnemosyne) Touch(ctx context.Context, token Token) (*Session, error) {
+	return m.TouchWithTTL(ctx, token, 0)
+}
+
+// TouchWithTTL implements Mnemosyne interface.
+// Session expires once given lifetime passes, it is rounded up to whole seconds and 0 means server default.
+// Server can reject lifetime above its limit.
+func (m *mnemosyne) TouchWithTTL(ctx context.Context, token Token, ttl time.Duration) (*Session, error) {
+	res, err := m.client.Touch(m.outgoing(ctx), &TouchRequest{
+		Token: &token,
+		Ttl:   int64((ttl + time.Second - 1) / time.Second),
+	})
+	if err != nil {
+		m.uncached(token)
+		return nil, err
+	}
+
+	m.cached(token, res.Session)
+
+	return res.Session, nil
+}
+
 // SetData implements Mnemosyne interface.
 func (m *mnemosyne) SetValue(ctx context.Context, token Token, key, value string) (map[string]string, error) {
-	res, err := m.client.SetValue(ctx, &SetValueRequest{
+	defer m.uncached(token)
+
+	res, err := m.client.SetValue(m.outgoing(ctx), &SetValueRequest{
 		Token: &token,
 		Key:   key,
 		Value: value,
@@ -124,34 +240,68 @@ func (m *mnemosyne) SetValue(ctx context.Context, token Token, key, value string
 	return res.Bag, nil
 }
 
-//// DeleteValue implements Mnemosyne interface.
-//func (m *mnemosyne) DeleteValue(ctx context.Context, key string) (*Session, error) {
-//	token, ok := TokenFromContext(ctx)
-//	if !ok {
-//		return nil, errors.New("mnemosyne: session value cannot be deleted, missing session token in the context")
-//	}
-//	res, err := m.client.DeleteValue(ctx, &DeleteValueRequest{
-//		Token: &token,
-//		Key:   key,
-//	})
-//
-//	if err != nil {
-//		return nil, err
-//	}
-//
-//	return res.Session, nil
-//}
+// DeleteValue implements Mnemosyne interface.
+func (m *mnemosyne) DeleteValue(ctx context.Context, token Token, key string) (*Session, error) {
+	res, err := m.client.DeleteValue(m.outgoing(ctx), &DeleteValueRequest{
+		Token: &token,
+		Key:   key,
+	})
+	if err != nil {
+		m.uncached(token)
+		return nil, err
+	}
 
-//// Clear ...
-//func (m *mnemosyne) Clear(ctx context.Context) error {
-//	token, ok := TokenFromContext(ctx)
-//	if !ok {
-//		return errors.New("mnemosyne: session bag cannot be cleared, missing session token in the context")
-//	}
-//	_, err := m.client.Clear(ctx, &ClearRequest{Token: &token})
-//
-//	return err
-//}
+	m.cached(token, res.Session)
+
+	return res.Session, nil
+}
+
+// Clear implements Mnemosyne interface.
+func (m *mnemosyne) Clear(ctx context.Context, token Token) error {
+	defer m.uncached(token)
+
+	_, err := m.client.Clear(m.outgoing(ctx), &ClearRequest{Token: &token})
+
+	return err
+}
+
+// Watch implements Mnemosyne interface.
+// It opens stream of lifecycle events of sessions that belong to given subject or all sessions if subject id is empty.
+// Stream is closed by the server if client is not able to keep up with incoming events.
+func (m *mnemosyne) Watch(ctx context.Context, subjectID string) (RPC_WatchClient, error) {
+	return m.client.Watch(m.outgoing(ctx), &WatchRequest{SubjectId: subjectID})
+}
+
+// outgoing returns context that carries static metadata and session token (if any), next to metadata that is already there.
+func (m *mnemosyne) outgoing(ctx context.Context) context.Context {
+	md, _ := metadata.FromContext(ctx)
+
+	return withToken(ctx, metadata.Join(md, m.metadata))
+}
+
+func (m *mnemosyne) cached(token Token, ses *Session) {
+	if m.cache != nil {
+		m.cache.set(token.Encode(), ses)
+	}
+}
+
+func (m *mnemosyne) uncached(token Token) {
+	if m.cache != nil {
+		m.cache.del(token.Encode())
+	}
+}
+
+func (m *mnemosyne) uncachedSubject(subjectID string, except *Token) {
+	if m.cache == nil {
+		return
+	}
+
+	var key string
+	if except != nil {
+		key = except.Encode()
+	}
+	m.cache.delSubject(subjectID, key)
+}
 
 // Context implements sklog.Contexter interface.
 func (gr *GetRequest) Context() []interface{} {
@@ -165,6 +315,9 @@ func (lr *ListRequest) Context() []interface{} {
 		"limit", lr.Limit,
 		"expire_at_from", lr.ExpireAtFrom,
 		"expire_at_to", lr.ExpireAtTo,
+		"subject_id", lr.SubjectId,
+		"page_token", lr.PageToken,
+		"bag_key", lr.BagKey,
 	}
 }
 
@@ -175,6 +328,7 @@ func (er *ExistsRequest) Context() []interface{} {
 
 // Context implements sklog.Contexter interface.
 func (er *StartRequest) Context() (ctx []interface{}) {
+	ctx = append(ctx, "ttl", er.Ttl)
 	for key, value := range er.Bag {
 		ctx = append(ctx, "bag_"+key, value)
 	}
@@ -189,6 +343,24 @@ func (ar *AbandonRequest) Context() []interface{} {
 	}
 }
 
+// Context implements sklog.Contexter interface.
+func (aar *AbandonAllRequest) Context() []interface{} {
+	ctx := []interface{}{"subject_id", aar.SubjectId}
+	if aar.Except != nil {
+		ctx = append(ctx, "except", aar.Except.Bytes())
+	}
+
+	return ctx
+}
+
+// Context implements sklog.Contexter interface.
+func (tr *TouchRequest) Context() []interface{} {
+	return []interface{}{
+		"token", tr.Token.Bytes(),
+		"ttl", tr.Ttl,
+	}
+}
+
 // Context implements sklog.Contexter interface.
 func (svr *SetValueRequest) Context() []interface{} {
 	return []interface{}{
@@ -222,15 +394,7 @@ func (dr *DeleteRequest) Context() []interface{} {
 	}
 }
 
-//// TokenContextMiddleware puts token taken from header into current context.
-//func TokenContextMiddleware(header string) func(fn func(context.Context, http.ResponseWriter, *http.Request)) func(context.Context, http.ResponseWriter, *http.Request) {
-//	return func(fn func(context.Context, http.ResponseWriter, *http.Request)) func(context.Context, http.ResponseWriter, *http.Request) {
-//		return func(ctx context.Context, rw http.ResponseWriter, r *http.Request) {
-//			token := r.Header.Get(header)
-//			ctx = NewTokenContext(ctx, DecodeToken(token))
-//
-//			rw.Header().Set(header, token)
-//			fn(ctx, rw, r)
-//		}
-//	}
-//}
+// Context implements sklog.Contexter interface.
+func (wr *WatchRequest) Context() []interface{} {
+	return []interface{}{"subject_id", wr.SubjectId}
+}
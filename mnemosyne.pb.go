@@ -1,14 +1,15 @@
-// Code generated by protoc-gen-go.
+// Code generated by protoc-gen-go. DO NOT EDIT.
 // source: mnemosyne.proto
-// DO NOT EDIT!
 
 /*
 Package mnemosyne is a generated protocol buffer package.
 
 It is generated from these files:
+
 	mnemosyne.proto
 
 It has these top-level messages:
+
 	Empty
 	Token
 	Session
@@ -30,6 +31,12 @@ It has these top-level messages:
 	ClearResponse
 	DeleteRequest
 	DeleteResponse
+	TouchRequest
+	TouchResponse
+	AbandonAllRequest
+	AbandonAllResponse
+	WatchRequest
+	Event
 */
 package mnemosyne
 
@@ -48,6 +55,42 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Event_Type int32
+
+const (
+	Event_UNKNOWN   Event_Type = 0
+	Event_CREATED   Event_Type = 1
+	Event_UPDATED   Event_Type = 2
+	Event_ABANDONED Event_Type = 3
+	Event_EXPIRED   Event_Type = 4
+)
+
+var Event_Type_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "CREATED",
+	2: "UPDATED",
+	3: "ABANDONED",
+	4: "EXPIRED",
+}
+var Event_Type_value = map[string]int32{
+	"UNKNOWN":   0,
+	"CREATED":   1,
+	"UPDATED":   2,
+	"ABANDONED": 3,
+	"EXPIRED":   4,
+}
+
+func (x Event_Type) String() string {
+	return proto.EnumName(Event_Type_name, int32(x))
+}
+func (Event_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{26, 0} }
+
 type Empty struct {
 }
 
@@ -67,11 +110,25 @@ func (m *Token) String() string            { return proto.CompactTextString(m) }
 func (*Token) ProtoMessage()               {}
 func (*Token) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
 
+func (m *Token) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *Token) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
 type Session struct {
 	Token     *Token            `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
-	SubjectId string            `protobuf:"bytes,2,opt,name=subject_id" json:"subject_id,omitempty"`
+	SubjectId string            `protobuf:"bytes,2,opt,name=subject_id,json=subjectId" json:"subject_id,omitempty"`
 	Bag       map[string]string `protobuf:"bytes,3,rep,name=bag" json:"bag,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	ExpireAt  *protot.Timestamp `protobuf:"bytes,4,opt,name=expire_at" json:"expire_at,omitempty"`
+	ExpireAt  *protot.Timestamp `protobuf:"bytes,4,opt,name=expire_at,json=expireAt" json:"expire_at,omitempty"`
 }
 
 func (m *Session) Reset()                    { *m = Session{} }
@@ -86,6 +143,13 @@ func (m *Session) GetToken() *Token {
 	return nil
 }
 
+func (m *Session) GetSubjectId() string {
+	if m != nil {
+		return m.SubjectId
+	}
+	return ""
+}
+
 func (m *Session) GetBag() map[string]string {
 	if m != nil {
 		return m.Bag
@@ -133,10 +197,17 @@ func (m *GetResponse) GetSession() *Session {
 }
 
 type ListRequest struct {
+	// offset is not supported anymore, page_token should be used instead.
 	Offset       int64             `protobuf:"varint,1,opt,name=offset" json:"offset,omitempty"`
 	Limit        int64             `protobuf:"varint,2,opt,name=limit" json:"limit,omitempty"`
-	ExpireAtFrom *protot.Timestamp `protobuf:"bytes,3,opt,name=expire_at_from" json:"expire_at_from,omitempty"`
-	ExpireAtTo   *protot.Timestamp `protobuf:"bytes,4,opt,name=expire_at_to" json:"expire_at_to,omitempty"`
+	ExpireAtFrom *protot.Timestamp `protobuf:"bytes,3,opt,name=expire_at_from,json=expireAtFrom" json:"expire_at_from,omitempty"`
+	ExpireAtTo   *protot.Timestamp `protobuf:"bytes,4,opt,name=expire_at_to,json=expireAtTo" json:"expire_at_to,omitempty"`
+	// subject_id if provided, only sessions of given subject are returned.
+	SubjectId string `protobuf:"bytes,5,opt,name=subject_id,json=subjectId" json:"subject_id,omitempty"`
+	// page_token if provided, listing continues right after the last session of the previous page.
+	PageToken string `protobuf:"bytes,6,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
+	// bag_key if provided, only sessions which bag contains given key are returned.
+	BagKey string `protobuf:"bytes,7,opt,name=bag_key,json=bagKey" json:"bag_key,omitempty"`
 }
 
 func (m *ListRequest) Reset()                    { *m = ListRequest{} }
@@ -144,6 +215,20 @@ func (m *ListRequest) String() string            { return proto.CompactTextStrin
 func (*ListRequest) ProtoMessage()               {}
 func (*ListRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
+func (m *ListRequest) GetOffset() int64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *ListRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
 func (m *ListRequest) GetExpireAtFrom() *protot.Timestamp {
 	if m != nil {
 		return m.ExpireAtFrom
@@ -158,8 +243,31 @@ func (m *ListRequest) GetExpireAtTo() *protot.Timestamp {
 	return nil
 }
 
+func (m *ListRequest) GetSubjectId() string {
+	if m != nil {
+		return m.SubjectId
+	}
+	return ""
+}
+
+func (m *ListRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *ListRequest) GetBagKey() string {
+	if m != nil {
+		return m.BagKey
+	}
+	return ""
+}
+
 type ListResponse struct {
 	Sessions []*Session `protobuf:"bytes,1,rep,name=sessions" json:"sessions,omitempty"`
+	// next_page_token is empty if there are no more sessions to retrieve.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
 }
 
 func (m *ListResponse) Reset()                    { *m = ListResponse{} }
@@ -174,6 +282,13 @@ func (m *ListResponse) GetSessions() []*Session {
 	return nil
 }
 
+func (m *ListResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type ExistsRequest struct {
 	Token *Token `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
 }
@@ -199,9 +314,18 @@ func (m *ExistsResponse) String() string            { return proto.CompactTextSt
 func (*ExistsResponse) ProtoMessage()               {}
 func (*ExistsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
+func (m *ExistsResponse) GetExists() bool {
+	if m != nil {
+		return m.Exists
+	}
+	return false
+}
+
 type StartRequest struct {
-	SubjectId string            `protobuf:"bytes,1,opt,name=subject_id" json:"subject_id,omitempty"`
+	SubjectId string            `protobuf:"bytes,1,opt,name=subject_id,json=subjectId" json:"subject_id,omitempty"`
 	Bag       map[string]string `protobuf:"bytes,2,rep,name=bag" json:"bag,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// ttl is session lifetime in seconds, if not provided server default is used.
+	Ttl int64 `protobuf:"varint,3,opt,name=ttl" json:"ttl,omitempty"`
 }
 
 func (m *StartRequest) Reset()                    { *m = StartRequest{} }
@@ -209,6 +333,13 @@ func (m *StartRequest) String() string            { return proto.CompactTextStri
 func (*StartRequest) ProtoMessage()               {}
 func (*StartRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
+func (m *StartRequest) GetSubjectId() string {
+	if m != nil {
+		return m.SubjectId
+	}
+	return ""
+}
+
 func (m *StartRequest) GetBag() map[string]string {
 	if m != nil {
 		return m.Bag
@@ -216,6 +347,13 @@ func (m *StartRequest) GetBag() map[string]string {
 	return nil
 }
 
+func (m *StartRequest) GetTtl() int64 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
 type StartResponse struct {
 	Session *Session `protobuf:"bytes,1,opt,name=session" json:"session,omitempty"`
 }
@@ -257,6 +395,13 @@ func (m *AbandonResponse) String() string            { return proto.CompactTextS
 func (*AbandonResponse) ProtoMessage()               {}
 func (*AbandonResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
 
+func (m *AbandonResponse) GetAbandoned() bool {
+	if m != nil {
+		return m.Abandoned
+	}
+	return false
+}
+
 type SetValueRequest struct {
 	Token *Token `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
 	Key   string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
@@ -275,6 +420,20 @@ func (m *SetValueRequest) GetToken() *Token {
 	return nil
 }
 
+func (m *SetValueRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *SetValueRequest) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
 type SetValueResponse struct {
 	Bag map[string]string `protobuf:"bytes,1,rep,name=bag" json:"bag,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
@@ -308,6 +467,13 @@ func (m *DeleteValueRequest) GetToken() *Token {
 	return nil
 }
 
+func (m *DeleteValueRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
 type DeleteValueResponse struct {
 	Session *Session `protobuf:"bytes,1,opt,name=session" json:"session,omitempty"`
 }
@@ -350,8 +516,8 @@ func (*ClearResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []i
 
 type DeleteRequest struct {
 	Token        *Token            `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
-	ExpireAtFrom *protot.Timestamp `protobuf:"bytes,2,opt,name=expire_at_from" json:"expire_at_from,omitempty"`
-	ExpireAtTo   *protot.Timestamp `protobuf:"bytes,3,opt,name=expire_at_to" json:"expire_at_to,omitempty"`
+	ExpireAtFrom *protot.Timestamp `protobuf:"bytes,2,opt,name=expire_at_from,json=expireAtFrom" json:"expire_at_from,omitempty"`
+	ExpireAtTo   *protot.Timestamp `protobuf:"bytes,3,opt,name=expire_at_to,json=expireAtTo" json:"expire_at_to,omitempty"`
 }
 
 func (m *DeleteRequest) Reset()                    { *m = DeleteRequest{} }
@@ -389,6 +555,136 @@ func (m *DeleteResponse) String() string            { return proto.CompactTextSt
 func (*DeleteResponse) ProtoMessage()               {}
 func (*DeleteResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
 
+func (m *DeleteResponse) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type TouchRequest struct {
+	Token *Token `protobuf:"bytes,1,opt,name=token" json:"token,omitempty"`
+	// ttl is session lifetime in seconds counted from now, if not provided server default is used.
+	Ttl int64 `protobuf:"varint,2,opt,name=ttl" json:"ttl,omitempty"`
+}
+
+func (m *TouchRequest) Reset()                    { *m = TouchRequest{} }
+func (m *TouchRequest) String() string            { return proto.CompactTextString(m) }
+func (*TouchRequest) ProtoMessage()               {}
+func (*TouchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *TouchRequest) GetToken() *Token {
+	if m != nil {
+		return m.Token
+	}
+	return nil
+}
+
+func (m *TouchRequest) GetTtl() int64 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+type TouchResponse struct {
+	Session *Session `protobuf:"bytes,1,opt,name=session" json:"session,omitempty"`
+}
+
+func (m *TouchResponse) Reset()                    { *m = TouchResponse{} }
+func (m *TouchResponse) String() string            { return proto.CompactTextString(m) }
+func (*TouchResponse) ProtoMessage()               {}
+func (*TouchResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *TouchResponse) GetSession() *Session {
+	if m != nil {
+		return m.Session
+	}
+	return nil
+}
+
+type AbandonAllRequest struct {
+	SubjectId string `protobuf:"bytes,1,opt,name=subject_id,json=subjectId" json:"subject_id,omitempty"`
+	// except if provided, given session is not abandoned (e.g. session of the caller).
+	Except *Token `protobuf:"bytes,2,opt,name=except" json:"except,omitempty"`
+}
+
+func (m *AbandonAllRequest) Reset()                    { *m = AbandonAllRequest{} }
+func (m *AbandonAllRequest) String() string            { return proto.CompactTextString(m) }
+func (*AbandonAllRequest) ProtoMessage()               {}
+func (*AbandonAllRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *AbandonAllRequest) GetSubjectId() string {
+	if m != nil {
+		return m.SubjectId
+	}
+	return ""
+}
+
+func (m *AbandonAllRequest) GetExcept() *Token {
+	if m != nil {
+		return m.Except
+	}
+	return nil
+}
+
+type AbandonAllResponse struct {
+	Count int64 `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
+}
+
+func (m *AbandonAllResponse) Reset()                    { *m = AbandonAllResponse{} }
+func (m *AbandonAllResponse) String() string            { return proto.CompactTextString(m) }
+func (*AbandonAllResponse) ProtoMessage()               {}
+func (*AbandonAllResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *AbandonAllResponse) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type WatchRequest struct {
+	// subject_id if provided, only events of sessions of given subject are streamed.
+	SubjectId string `protobuf:"bytes,1,opt,name=subject_id,json=subjectId" json:"subject_id,omitempty"`
+}
+
+func (m *WatchRequest) Reset()                    { *m = WatchRequest{} }
+func (m *WatchRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()               {}
+func (*WatchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *WatchRequest) GetSubjectId() string {
+	if m != nil {
+		return m.SubjectId
+	}
+	return ""
+}
+
+type Event struct {
+	Type    Event_Type `protobuf:"varint,1,opt,name=type,enum=mnemosyne.Event_Type" json:"type,omitempty"`
+	Session *Session   `protobuf:"bytes,2,opt,name=session" json:"session,omitempty"`
+}
+
+func (m *Event) Reset()                    { *m = Event{} }
+func (m *Event) String() string            { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()               {}
+func (*Event) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *Event) GetType() Event_Type {
+	if m != nil {
+		return m.Type
+	}
+	return Event_UNKNOWN
+}
+
+func (m *Event) GetSession() *Session {
+	if m != nil {
+		return m.Session
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Empty)(nil), "mnemosyne.Empty")
 	proto.RegisterType((*Token)(nil), "mnemosyne.Token")
@@ -411,12 +707,23 @@ func init() {
 	proto.RegisterType((*ClearResponse)(nil), "mnemosyne.ClearResponse")
 	proto.RegisterType((*DeleteRequest)(nil), "mnemosyne.DeleteRequest")
 	proto.RegisterType((*DeleteResponse)(nil), "mnemosyne.DeleteResponse")
+	proto.RegisterType((*TouchRequest)(nil), "mnemosyne.TouchRequest")
+	proto.RegisterType((*TouchResponse)(nil), "mnemosyne.TouchResponse")
+	proto.RegisterType((*AbandonAllRequest)(nil), "mnemosyne.AbandonAllRequest")
+	proto.RegisterType((*AbandonAllResponse)(nil), "mnemosyne.AbandonAllResponse")
+	proto.RegisterType((*WatchRequest)(nil), "mnemosyne.WatchRequest")
+	proto.RegisterType((*Event)(nil), "mnemosyne.Event")
+	proto.RegisterEnum("mnemosyne.Event_Type", Event_Type_name, Event_Type_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ context.Context
 var _ grpc.ClientConn
 
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
 // Client API for RPC service
 
 type RPCClient interface {
@@ -426,10 +733,13 @@ type RPCClient interface {
 	Exists(ctx context.Context, in *ExistsRequest, opts ...grpc.CallOption) (*ExistsResponse, error)
 	Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*StartResponse, error)
 	Abandon(ctx context.Context, in *AbandonRequest, opts ...grpc.CallOption) (*AbandonResponse, error)
+	Touch(ctx context.Context, in *TouchRequest, opts ...grpc.CallOption) (*TouchResponse, error)
+	AbandonAll(ctx context.Context, in *AbandonAllRequest, opts ...grpc.CallOption) (*AbandonAllResponse, error)
 	SetValue(ctx context.Context, in *SetValueRequest, opts ...grpc.CallOption) (*SetValueResponse, error)
-	//    rpc DeleteValue(DeleteValueRequest) returns (DeleteValueResponse) {};
-	//    rpc Clear(ClearRequest) returns (ClearResponse) {};
+	DeleteValue(ctx context.Context, in *DeleteValueRequest, opts ...grpc.CallOption) (*DeleteValueResponse, error)
+	Clear(ctx context.Context, in *ClearRequest, opts ...grpc.CallOption) (*ClearResponse, error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (RPC_WatchClient, error)
 }
 
 type rPCClient struct {
@@ -494,6 +804,24 @@ func (c *rPCClient) Abandon(ctx context.Context, in *AbandonRequest, opts ...grp
 	return out, nil
 }
 
+func (c *rPCClient) Touch(ctx context.Context, in *TouchRequest, opts ...grpc.CallOption) (*TouchResponse, error) {
+	out := new(TouchResponse)
+	err := grpc.Invoke(ctx, "/mnemosyne.RPC/Touch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCClient) AbandonAll(ctx context.Context, in *AbandonAllRequest, opts ...grpc.CallOption) (*AbandonAllResponse, error) {
+	out := new(AbandonAllResponse)
+	err := grpc.Invoke(ctx, "/mnemosyne.RPC/AbandonAll", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *rPCClient) SetValue(ctx context.Context, in *SetValueRequest, opts ...grpc.CallOption) (*SetValueResponse, error) {
 	out := new(SetValueResponse)
 	err := grpc.Invoke(ctx, "/mnemosyne.RPC/SetValue", in, out, c.cc, opts...)
@@ -503,6 +831,24 @@ func (c *rPCClient) SetValue(ctx context.Context, in *SetValueRequest, opts ...g
 	return out, nil
 }
 
+func (c *rPCClient) DeleteValue(ctx context.Context, in *DeleteValueRequest, opts ...grpc.CallOption) (*DeleteValueResponse, error) {
+	out := new(DeleteValueResponse)
+	err := grpc.Invoke(ctx, "/mnemosyne.RPC/DeleteValue", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCClient) Clear(ctx context.Context, in *ClearRequest, opts ...grpc.CallOption) (*ClearResponse, error) {
+	out := new(ClearResponse)
+	err := grpc.Invoke(ctx, "/mnemosyne.RPC/Clear", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *rPCClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
 	out := new(DeleteResponse)
 	err := grpc.Invoke(ctx, "/mnemosyne.RPC/Delete", in, out, c.cc, opts...)
@@ -512,6 +858,38 @@ func (c *rPCClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.
 	return out, nil
 }
 
+func (c *rPCClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (RPC_WatchClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_RPC_serviceDesc.Streams[0], c.cc, "/mnemosyne.RPC/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rPCWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RPC_WatchClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type rPCWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *rPCWatchClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for RPC service
 
 type RPCServer interface {
@@ -521,110 +899,254 @@ type RPCServer interface {
 	Exists(context.Context, *ExistsRequest) (*ExistsResponse, error)
 	Start(context.Context, *StartRequest) (*StartResponse, error)
 	Abandon(context.Context, *AbandonRequest) (*AbandonResponse, error)
+	Touch(context.Context, *TouchRequest) (*TouchResponse, error)
+	AbandonAll(context.Context, *AbandonAllRequest) (*AbandonAllResponse, error)
 	SetValue(context.Context, *SetValueRequest) (*SetValueResponse, error)
-	//    rpc DeleteValue(DeleteValueRequest) returns (DeleteValueResponse) {};
-	//    rpc Clear(ClearRequest) returns (ClearResponse) {};
+	DeleteValue(context.Context, *DeleteValueRequest) (*DeleteValueResponse, error)
+	Clear(context.Context, *ClearRequest) (*ClearResponse, error)
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	Watch(*WatchRequest, RPC_WatchServer) error
 }
 
 func RegisterRPCServer(s *grpc.Server, srv RPCServer) {
 	s.RegisterService(&_RPC_serviceDesc, srv)
 }
 
-func _RPC_Context_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _RPC_Context_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(RPCServer).Context(ctx, in)
-	if err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(RPCServer).Context(ctx, in)
 	}
-	return out, nil
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mnemosyne.RPC/Context",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServer).Context(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _RPC_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _RPC_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(RPCServer).Get(ctx, in)
-	if err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(RPCServer).Get(ctx, in)
 	}
-	return out, nil
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mnemosyne.RPC/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _RPC_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _RPC_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(RPCServer).List(ctx, in)
-	if err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(RPCServer).List(ctx, in)
 	}
-	return out, nil
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mnemosyne.RPC/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _RPC_Exists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _RPC_Exists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ExistsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(RPCServer).Exists(ctx, in)
-	if err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(RPCServer).Exists(ctx, in)
 	}
-	return out, nil
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mnemosyne.RPC/Exists",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServer).Exists(ctx, req.(*ExistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _RPC_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _RPC_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(StartRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(RPCServer).Start(ctx, in)
-	if err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(RPCServer).Start(ctx, in)
 	}
-	return out, nil
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mnemosyne.RPC/Start",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServer).Start(ctx, req.(*StartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _RPC_Abandon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _RPC_Abandon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AbandonRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(RPCServer).Abandon(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(RPCServer).Abandon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mnemosyne.RPC/Abandon",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServer).Abandon(ctx, req.(*AbandonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPC_Touch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TouchRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(RPCServer).Touch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mnemosyne.RPC/Touch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServer).Touch(ctx, req.(*TouchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _RPC_SetValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _RPC_AbandonAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AbandonAllRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServer).AbandonAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mnemosyne.RPC/AbandonAll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServer).AbandonAll(ctx, req.(*AbandonAllRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPC_SetValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SetValueRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(RPCServer).SetValue(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(RPCServer).SetValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mnemosyne.RPC/SetValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServer).SetValue(ctx, req.(*SetValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPC_DeleteValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteValueRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(RPCServer).DeleteValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mnemosyne.RPC/DeleteValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServer).DeleteValue(ctx, req.(*DeleteValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _RPC_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(DeleteRequest)
+func _RPC_Clear_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(RPCServer).Delete(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(RPCServer).Clear(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mnemosyne.RPC/Clear",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServer).Clear(ctx, req.(*ClearRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPC_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(RPCServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/mnemosyne.RPC/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPC_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RPCServer).Watch(m, &rPCWatchServer{stream})
+}
+
+type RPC_WatchServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type rPCWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *rPCWatchServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
 }
 
 var _RPC_serviceDesc = grpc.ServiceDesc{
@@ -655,60 +1177,107 @@ var _RPC_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Abandon",
 			Handler:    _RPC_Abandon_Handler,
 		},
+		{
+			MethodName: "Touch",
+			Handler:    _RPC_Touch_Handler,
+		},
+		{
+			MethodName: "AbandonAll",
+			Handler:    _RPC_AbandonAll_Handler,
+		},
 		{
 			MethodName: "SetValue",
 			Handler:    _RPC_SetValue_Handler,
 		},
+		{
+			MethodName: "DeleteValue",
+			Handler:    _RPC_DeleteValue_Handler,
+		},
+		{
+			MethodName: "Clear",
+			Handler:    _RPC_Clear_Handler,
+		},
 		{
 			MethodName: "Delete",
 			Handler:    _RPC_Delete_Handler,
 		},
 	},
-	Streams: []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _RPC_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "mnemosyne.proto",
 }
 
+func init() { proto.RegisterFile("mnemosyne.proto", fileDescriptor0) }
+
 var fileDescriptor0 = []byte{
-	// 660 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x54, 0xcf, 0x6f, 0x12, 0x41,
-	0x14, 0xee, 0xb2, 0xa5, 0xb0, 0x8f, 0x05, 0xda, 0x31, 0xda, 0x75, 0x7b, 0x28, 0x19, 0x49, 0xac,
-	0x26, 0x45, 0x8b, 0x8d, 0xd1, 0xc6, 0xc4, 0x08, 0x12, 0x2f, 0xc6, 0x98, 0xd2, 0x78, 0x25, 0x4b,
-	0x19, 0xda, 0xb5, 0xec, 0x0e, 0xdd, 0x19, 0x0c, 0x1c, 0x4c, 0x3c, 0xf8, 0x57, 0xf9, 0x87, 0x79,
-	0x76, 0x77, 0x66, 0x58, 0x76, 0x05, 0x74, 0xe9, 0x71, 0xde, 0xbc, 0xef, 0xbd, 0xef, 0x7d, 0xef,
-	0x07, 0x54, 0x3d, 0x9f, 0x78, 0x94, 0xcd, 0x7c, 0xd2, 0x18, 0x07, 0x94, 0x53, 0x64, 0xc4, 0x06,
-	0xdb, 0x14, 0x16, 0x2e, 0x3f, 0x70, 0x01, 0xf2, 0x1d, 0x6f, 0xcc, 0x67, 0x18, 0x43, 0xfe, 0x82,
-	0xde, 0x10, 0x1f, 0x95, 0x40, 0xbf, 0x21, 0x33, 0x4b, 0xab, 0x69, 0x47, 0x26, 0x32, 0x61, 0xfb,
-	0xda, 0x61, 0xd7, 0x56, 0x2e, 0x7a, 0xe1, 0x5f, 0x1a, 0x14, 0xba, 0x84, 0x31, 0x97, 0xfa, 0xe8,
-	0x10, 0xf2, 0x3c, 0xf2, 0x17, 0x8e, 0xa5, 0xe6, 0x6e, 0x63, 0x91, 0x52, 0xc6, 0x41, 0x00, 0x6c,
-	0xd2, 0xff, 0x4a, 0x2e, 0x79, 0xcf, 0x1d, 0x88, 0x00, 0x06, 0x3a, 0x02, 0xbd, 0xef, 0x5c, 0x59,
-	0x7a, 0x4d, 0x0f, 0x21, 0x07, 0x09, 0x88, 0x8a, 0xda, 0x68, 0x39, 0x57, 0x1d, 0x9f, 0x07, 0x33,
-	0x54, 0x07, 0x83, 0x4c, 0xc7, 0x6e, 0x40, 0x7a, 0x0e, 0xb7, 0xb6, 0x45, 0x8a, 0xbd, 0x86, 0x62,
-	0x7e, 0xe1, 0x7a, 0x84, 0x71, 0xc7, 0x1b, 0xdb, 0x4f, 0xa1, 0x18, 0x23, 0x12, 0xbc, 0x0d, 0x54,
-	0x86, 0xfc, 0x37, 0x67, 0x34, 0x21, 0x32, 0xef, 0x59, 0xee, 0x95, 0x86, 0x8f, 0x01, 0x3e, 0x10,
-	0x7e, 0x4e, 0x6e, 0x27, 0x21, 0xf8, 0xbf, 0xf4, 0x71, 0x13, 0x4a, 0xc2, 0x9d, 0x8d, 0xa9, 0xcf,
-	0x08, 0x7a, 0x04, 0x05, 0x26, 0x39, 0x2a, 0x04, 0x5a, 0x66, 0x8f, 0x7f, 0x68, 0x50, 0xfa, 0xe8,
-	0xb2, 0x38, 0x49, 0x05, 0x76, 0xe8, 0x70, 0xc8, 0x08, 0x17, 0x18, 0x3d, 0x62, 0x35, 0x72, 0x3d,
-	0x97, 0x0b, 0x56, 0x3a, 0x7a, 0x02, 0x95, 0xb8, 0xc6, 0xde, 0x30, 0xa0, 0x5e, 0x28, 0xcc, 0xea,
-	0x42, 0xd1, 0x63, 0x30, 0x17, 0xae, 0x9c, 0xae, 0x55, 0x04, 0x9f, 0x82, 0x29, 0x19, 0x28, 0xde,
-	0x75, 0x28, 0x2a, 0xde, 0x2c, 0x24, 0xa1, 0xaf, 0x21, 0xfe, 0x1c, 0xca, 0x9d, 0x69, 0x08, 0x63,
-	0x99, 0xe5, 0xa9, 0x41, 0x65, 0x8e, 0x50, 0x99, 0xc2, 0x62, 0x89, 0xb0, 0x08, 0x4c, 0x11, 0x7f,
-	0x07, 0xb3, 0xcb, 0x9d, 0x20, 0x16, 0x23, 0x3d, 0x0f, 0xb2, 0x4d, 0xc7, 0x72, 0x1e, 0x72, 0x82,
-	0x58, 0x2d, 0x49, 0x2c, 0x81, 0x8c, 0x87, 0x62, 0xa3, 0x76, 0x9f, 0x42, 0x59, 0x05, 0xd9, 0xa4,
-	0x83, 0x27, 0x50, 0x79, 0xd7, 0x77, 0xfc, 0x01, 0xf5, 0x33, 0x2b, 0x51, 0x87, 0x6a, 0x0c, 0x51,
-	0xa9, 0xf6, 0xc0, 0x70, 0xa4, 0x89, 0x0c, 0x94, 0x1a, 0x9f, 0xa0, 0xda, 0x25, 0xfc, 0x4b, 0x44,
-	0x32, 0x6b, 0xe4, 0x79, 0x89, 0xb9, 0x74, 0x89, 0xd1, 0x8c, 0x18, 0xf8, 0x16, 0x76, 0x17, 0xf1,
-	0x54, 0xda, 0x13, 0xa9, 0xa6, 0x6c, 0x73, 0x3d, 0x55, 0x5d, 0xda, 0xf3, 0x6e, 0x8a, 0xb6, 0x00,
-	0xbd, 0x27, 0x23, 0xc2, 0xc9, 0xdd, 0xab, 0xc0, 0x67, 0x70, 0x2f, 0x15, 0x63, 0x93, 0xde, 0x3c,
-	0x03, 0xb3, 0x3d, 0x22, 0x4e, 0x90, 0xb9, 0x33, 0x55, 0x28, 0x2b, 0x80, 0x4c, 0x83, 0x7f, 0x6a,
-	0x50, 0x96, 0xe9, 0x33, 0xb3, 0x5f, 0xde, 0xd1, 0x5c, 0xd6, 0x1d, 0x5d, 0xb7, 0xcc, 0xf8, 0x10,
-	0x2a, 0x73, 0x16, 0xaa, 0xfe, 0x50, 0xed, 0x4b, 0x3a, 0xf1, 0xd5, 0x9d, 0x68, 0xfe, 0xd6, 0x41,
-	0x3f, 0xff, 0xdc, 0x0e, 0x1b, 0x5a, 0x68, 0x53, 0x9f, 0x93, 0x29, 0x47, 0x49, 0x66, 0xe2, 0x60,
-	0xdb, 0xab, 0x24, 0xda, 0x42, 0x2f, 0x41, 0x0f, 0xcf, 0x16, 0xba, 0x9f, 0xf8, 0x5c, 0x5c, 0x3d,
-	0xfb, 0xc1, 0xdf, 0x66, 0x25, 0xcc, 0x16, 0x7a, 0x0d, 0xdb, 0xd1, 0xdd, 0x40, 0x49, 0x8f, 0xc4,
-	0x29, 0xb3, 0xf7, 0x97, 0xec, 0x31, 0xf4, 0x2d, 0xec, 0xc8, 0x53, 0x80, 0xac, 0x24, 0xc9, 0xe4,
-	0x3d, 0xb1, 0x1f, 0xae, 0xf8, 0x89, 0x03, 0xbc, 0x81, 0xbc, 0x58, 0x55, 0xb4, 0xbf, 0xe6, 0x02,
-	0xd8, 0xd6, 0xf2, 0x47, 0x8c, 0x6e, 0x41, 0x41, 0xed, 0x1f, 0x4a, 0x66, 0x49, 0xaf, 0xb1, 0x6d,
-	0xaf, 0xfa, 0x8a, 0x63, 0x74, 0xa0, 0x38, 0xdf, 0x11, 0x64, 0xaf, 0x5c, 0x1c, 0x19, 0xe5, 0xe0,
-	0x1f, 0x4b, 0x25, 0x95, 0x90, 0x8d, 0x4d, 0x29, 0x91, 0x9a, 0xb8, 0x94, 0x12, 0xe9, 0x29, 0xc0,
-	0x5b, 0xfd, 0x1d, 0x31, 0x2b, 0x2f, 0xfe, 0x04, 0x00, 0x00, 0xff, 0xff, 0xa4, 0x13, 0x50, 0xb1,
-	0xc0, 0x07, 0x00, 0x00,
+	// 1015 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xeb, 0x6e, 0xe3, 0x44,
+	0x14, 0x8e, 0xed, 0x5c, 0x4f, 0xae, 0x3b, 0x2c, 0xad, 0xd7, 0xbb, 0x45, 0xd5, 0x08, 0x55, 0x05,
+	0xd1, 0x00, 0x59, 0xd4, 0x5d, 0x6e, 0x42, 0x69, 0x62, 0x96, 0xaa, 0x28, 0x5b, 0xb9, 0x81, 0xe5,
+	0x07, 0x52, 0xe4, 0xa4, 0xd3, 0x34, 0x6c, 0x62, 0x9b, 0x78, 0xb2, 0x6a, 0xfe, 0x21, 0xde, 0x85,
+	0x5f, 0x3c, 0x08, 0x8f, 0xc2, 0x6b, 0xa0, 0xb9, 0xd8, 0x19, 0xe7, 0xb2, 0x34, 0xd1, 0xfe, 0xf3,
+	0x9c, 0x33, 0xe7, 0xf6, 0x9d, 0x39, 0xdf, 0x31, 0x54, 0x27, 0x1e, 0x99, 0xf8, 0xe1, 0xdc, 0x23,
+	0xf5, 0x60, 0xea, 0x53, 0x1f, 0x15, 0x62, 0x81, 0x55, 0xe2, 0x12, 0x2a, 0x14, 0x38, 0x07, 0x19,
+	0x7b, 0x12, 0xd0, 0x39, 0x3e, 0x81, 0x4c, 0xd7, 0x7f, 0x4d, 0x3c, 0x54, 0x03, 0xe3, 0x35, 0x99,
+	0x9b, 0xda, 0xa1, 0x76, 0x5c, 0x72, 0xd8, 0x27, 0x42, 0x90, 0xbe, 0x75, 0xc3, 0x5b, 0x53, 0xe7,
+	0x22, 0xfe, 0x8d, 0xff, 0xd5, 0x20, 0x77, 0x45, 0xc2, 0x70, 0xe4, 0x7b, 0xe8, 0x08, 0x32, 0x94,
+	0x99, 0x72, 0x9b, 0x62, 0xa3, 0x56, 0x5f, 0x44, 0xe7, 0x2e, 0x1d, 0xa1, 0x46, 0x07, 0x00, 0xe1,
+	0xac, 0xff, 0x1b, 0x19, 0xd0, 0xde, 0xe8, 0x9a, 0x7b, 0x2b, 0x38, 0x05, 0x29, 0x39, 0xbf, 0x46,
+	0x27, 0x60, 0xf4, 0xdd, 0xa1, 0x69, 0x1c, 0x1a, 0xc7, 0xc5, 0xc6, 0x63, 0xc5, 0x89, 0x8c, 0x53,
+	0x3f, 0x73, 0x87, 0xb6, 0x47, 0xa7, 0x73, 0x87, 0xdd, 0x43, 0x75, 0x28, 0x90, 0xbb, 0x60, 0x34,
+	0x25, 0x3d, 0x97, 0x9a, 0x69, 0x1e, 0xf9, 0x41, 0x5d, 0xd6, 0xd6, 0x1d, 0x4d, 0x48, 0x48, 0xdd,
+	0x49, 0xe0, 0xe4, 0xc5, 0x9d, 0x26, 0xb5, 0x4e, 0x21, 0x1f, 0x39, 0x50, 0x6b, 0x2c, 0x88, 0x1a,
+	0x1f, 0x42, 0xe6, 0x8d, 0x3b, 0x9e, 0x11, 0x99, 0x96, 0x38, 0x7c, 0xa5, 0x3f, 0xd7, 0xf0, 0x17,
+	0x00, 0x2f, 0x08, 0x75, 0xc8, 0xef, 0x33, 0x12, 0xd2, 0xfb, 0xd6, 0x8a, 0xbf, 0x86, 0x22, 0xb7,
+	0x0a, 0x03, 0xdf, 0x0b, 0x09, 0xfa, 0x04, 0x72, 0xa1, 0xa8, 0x42, 0x1a, 0xa2, 0xd5, 0xfa, 0x9c,
+	0xe8, 0x0a, 0xfe, 0x43, 0x87, 0xe2, 0x8f, 0xa3, 0x30, 0x0e, 0xba, 0x07, 0x59, 0xff, 0xe6, 0x26,
+	0x24, 0x94, 0x1b, 0x1b, 0x8e, 0x3c, 0xb1, 0xa4, 0xc7, 0xa3, 0xc9, 0x88, 0xf2, 0xa4, 0x0d, 0x47,
+	0x1c, 0xd0, 0x33, 0xa8, 0xc4, 0xc0, 0xf4, 0x6e, 0xa6, 0xfe, 0xc4, 0x34, 0x36, 0xa1, 0x53, 0x8a,
+	0xd0, 0xf9, 0x7e, 0xea, 0x4f, 0xd0, 0x53, 0x28, 0x2d, 0x0c, 0xa9, 0xbf, 0x19, 0x54, 0x88, 0xcc,
+	0xba, 0xfe, 0x52, 0x53, 0x33, 0xcb, 0x4d, 0x3d, 0x00, 0x08, 0xdc, 0x21, 0xe9, 0x09, 0xd0, 0xb2,
+	0x42, 0xcd, 0x24, 0xe2, 0xb1, 0xed, 0x43, 0xae, 0xef, 0x0e, 0x7b, 0xac, 0x19, 0x39, 0xae, 0xcb,
+	0xf6, 0xdd, 0xe1, 0x05, 0x99, 0xe3, 0x1b, 0x28, 0x09, 0x04, 0x24, 0x80, 0x75, 0xc8, 0x4b, 0x74,
+	0x42, 0x53, 0x3b, 0x34, 0x36, 0x20, 0x18, 0xdf, 0x41, 0x47, 0x50, 0xf5, 0xc8, 0x1d, 0xed, 0x29,
+	0xc1, 0x45, 0x67, 0xcb, 0x4c, 0x7c, 0x19, 0x25, 0x80, 0x9f, 0x41, 0xd9, 0xbe, 0x1b, 0x85, 0x34,
+	0xdc, 0xb6, 0xc1, 0xc7, 0x50, 0x89, 0x0c, 0x65, 0x8a, 0x7b, 0x90, 0x25, 0x5c, 0xc2, 0x4d, 0xf3,
+	0x8e, 0x3c, 0xe1, 0xbf, 0x35, 0x28, 0x5d, 0x51, 0x77, 0x1a, 0xb7, 0x33, 0x09, 0x99, 0xb6, 0x0c,
+	0x59, 0x43, 0xcc, 0x81, 0xce, 0xab, 0x3c, 0x54, 0xab, 0x54, 0x9c, 0x2c, 0x0d, 0x43, 0x0d, 0x0c,
+	0x4a, 0xc7, 0xbc, 0xd1, 0x86, 0xc3, 0x3e, 0x77, 0x7e, 0xee, 0xdf, 0x42, 0x59, 0xc6, 0xd9, 0xe9,
+	0xe9, 0x3e, 0x87, 0x4a, 0xb3, 0xef, 0x7a, 0xd7, 0xbe, 0xb7, 0x2d, 0xa0, 0x9f, 0x42, 0x35, 0xb6,
+	0x94, 0xa1, 0x9f, 0x40, 0xc1, 0x15, 0x22, 0x72, 0x2d, 0x41, 0x5d, 0x08, 0xb0, 0x0b, 0xd5, 0x2b,
+	0x42, 0x7f, 0x66, 0x99, 0x6f, 0x19, 0x2b, 0x02, 0x44, 0x5f, 0x03, 0x88, 0xa1, 0x00, 0x82, 0xff,
+	0xd4, 0xa0, 0xb6, 0x88, 0x21, 0xb3, 0x3a, 0x15, 0xfd, 0x11, 0xaf, 0xf0, 0xc3, 0x04, 0x18, 0xc9,
+	0x9b, 0xc9, 0x1e, 0xed, 0xdc, 0x91, 0x0e, 0xa0, 0x36, 0x19, 0x13, 0x4a, 0xde, 0x4d, 0xa9, 0xb8,
+	0x05, 0xef, 0x25, 0xfc, 0xed, 0xd4, 0xe7, 0x53, 0x28, 0xb5, 0xc6, 0xc4, 0x9d, 0x6e, 0xdb, 0xe5,
+	0x2a, 0x94, 0xa5, 0x9d, 0x08, 0x8b, 0xff, 0xd2, 0xa0, 0x2c, 0xd2, 0xd9, 0xb6, 0xb2, 0x55, 0x9e,
+	0xd3, 0x77, 0xe3, 0x39, 0xe3, 0x1e, 0x3c, 0x87, 0x8f, 0xa0, 0x12, 0xa5, 0x29, 0x01, 0x7b, 0x08,
+	0x99, 0x81, 0x3f, 0xf3, 0x22, 0x52, 0x16, 0x07, 0xfc, 0x03, 0x94, 0xba, 0xfe, 0x6c, 0x70, 0xbb,
+	0x43, 0x9f, 0xd8, 0x04, 0xeb, 0xf1, 0x04, 0xb3, 0x49, 0x94, 0x9e, 0x76, 0xea, 0xd0, 0xaf, 0xf0,
+	0x40, 0xce, 0x53, 0x73, 0x3c, 0xbe, 0x27, 0xf5, 0x1c, 0x33, 0x0a, 0x1b, 0x90, 0x80, 0x9a, 0xfa,
+	0x86, 0x6c, 0xa5, 0x1e, 0x7f, 0x0c, 0x48, 0xf5, 0xfe, 0x56, 0x48, 0x4e, 0xa0, 0xf4, 0xca, 0xa5,
+	0x83, 0xdb, 0xfb, 0x25, 0xc1, 0xf8, 0x32, 0x63, 0xbf, 0x21, 0x1e, 0x45, 0x1f, 0x41, 0x9a, 0xce,
+	0x03, 0xc2, 0xaf, 0x54, 0x1a, 0xef, 0x2b, 0xc9, 0x70, 0x7d, 0xbd, 0x3b, 0x0f, 0x88, 0xc3, 0xaf,
+	0xa8, 0xd8, 0xe8, 0xff, 0x8f, 0xcd, 0x39, 0xa4, 0x99, 0x2d, 0x2a, 0x42, 0xee, 0xa7, 0xce, 0x45,
+	0xe7, 0xe5, 0xab, 0x4e, 0x2d, 0xc5, 0x0e, 0x2d, 0xc7, 0x6e, 0x76, 0xed, 0x76, 0x4d, 0xe3, 0x9a,
+	0xcb, 0x36, 0x3f, 0xe8, 0xa8, 0x0c, 0x85, 0xe6, 0x59, 0xb3, 0xd3, 0x7e, 0xd9, 0xb1, 0xdb, 0x35,
+	0x83, 0xe9, 0xec, 0x5f, 0x2e, 0xcf, 0x1d, 0xbb, 0x5d, 0x4b, 0x37, 0xfe, 0xc9, 0x82, 0xe1, 0x5c,
+	0xb6, 0xd0, 0xe7, 0x90, 0x6b, 0xf9, 0x1e, 0x25, 0x77, 0x14, 0xa9, 0xa8, 0xf1, 0x9f, 0x2b, 0x6b,
+	0x4d, 0x32, 0x38, 0xc5, 0x88, 0xe4, 0x05, 0xa1, 0x48, 0xad, 0x6b, 0xf1, 0xa7, 0x61, 0xed, 0x2d,
+	0x8b, 0xe5, 0xc0, 0xa4, 0xd0, 0x97, 0x90, 0x66, 0xbb, 0x11, 0xa9, 0x37, 0x94, 0xdf, 0x05, 0x6b,
+	0x7f, 0x45, 0x1e, 0x9b, 0x7e, 0x07, 0x59, 0xb1, 0xb5, 0x90, 0xa9, 0x26, 0xa9, 0x6e, 0x40, 0xeb,
+	0xd1, 0x1a, 0x4d, 0xec, 0xe0, 0x1b, 0xc8, 0xf0, 0xf5, 0x80, 0xf6, 0x37, 0x2c, 0x26, 0xcb, 0x5c,
+	0x55, 0xc4, 0xd6, 0x67, 0x90, 0x93, 0xaf, 0x06, 0xa9, 0x51, 0x92, 0x1b, 0xc3, 0xb2, 0xd6, 0xa9,
+	0xd4, 0x0c, 0xf8, 0x58, 0x24, 0x32, 0x50, 0x47, 0xce, 0x32, 0x57, 0x15, 0xb1, 0xf5, 0x05, 0xc0,
+	0xe2, 0xdd, 0xa2, 0x27, 0xab, 0x91, 0x16, 0xc3, 0x62, 0x1d, 0x6c, 0xd0, 0xc6, 0xce, 0x6c, 0xc8,
+	0x47, 0x9c, 0x8f, 0xac, 0xb5, 0x8b, 0x40, 0x38, 0x7a, 0xfc, 0x96, 0x25, 0x81, 0x53, 0xa8, 0x03,
+	0x45, 0x85, 0x90, 0x91, 0x1a, 0x76, 0x95, 0xf8, 0xad, 0x0f, 0x36, 0xa9, 0x55, 0x84, 0x38, 0xc7,
+	0x26, 0x10, 0x52, 0xd9, 0xda, 0x32, 0x57, 0x15, 0xea, 0x13, 0x11, 0x6e, 0x13, 0x4f, 0x24, 0x41,
+	0xd1, 0xd6, 0xa3, 0x35, 0x9a, 0xd8, 0xc1, 0x29, 0x64, 0xf8, 0xb8, 0x27, 0xc2, 0xab, 0x04, 0x60,
+	0xd5, 0x96, 0x27, 0x19, 0xa7, 0x3e, 0xd3, 0xfa, 0x59, 0xce, 0xbf, 0x4f, 0xff, 0x1b, 0x00, 0xe5,
+	0x58, 0xb8, 0x9f, 0xbd, 0x0c, 0x00, 0x00,
 }
@@ -0,0 +1,195 @@
+package mnemosyne
+
+import (
+	"testing"
+	"time"
+
+	"github.com/piotrkowalczuk/protot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type rpcClientStub struct {
+	RPCClient
+	session  *Session
+	calls    int
+	metadata metadata.MD
+	start    *StartRequest
+	touch    *TouchRequest
+	// abandonAll is called while AbandonAll call is in progress.
+	abandonAll func()
+}
+
+func (rcs *rpcClientStub) Context(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Session, error) {
+	rcs.calls++
+	rcs.metadata, _ = metadata.FromContext(ctx)
+
+	return rcs.session, nil
+}
+
+func (rcs *rpcClientStub) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	rcs.calls++
+
+	return &GetResponse{Session: rcs.session}, nil
+}
+
+func (rcs *rpcClientStub) Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*StartResponse, error) {
+	rcs.calls++
+	rcs.start = in
+
+	return &StartResponse{Session: rcs.session}, nil
+}
+
+func (rcs *rpcClientStub) SetValue(ctx context.Context, in *SetValueRequest, opts ...grpc.CallOption) (*SetValueResponse, error) {
+	rcs.calls++
+
+	return &SetValueResponse{Bag: map[string]string{in.Key: in.Value}}, nil
+}
+
+func (rcs *rpcClientStub) Touch(ctx context.Context, in *TouchRequest, opts ...grpc.CallOption) (*TouchResponse, error) {
+	rcs.calls++
+	rcs.touch = in
+
+	return &TouchResponse{Session: rcs.session}, nil
+}
+
+func (rcs *rpcClientStub) AbandonAll(ctx context.Context, in *AbandonAllRequest, opts ...grpc.CallOption) (*AbandonAllResponse, error) {
+	rcs.calls++
+	if rcs.abandonAll != nil {
+		rcs.abandonAll()
+	}
+
+	return &AbandonAllResponse{Count: 1}, nil
+}
+
+func TestMnemosyne_cache(t *testing.T) {
+	token := NewToken([]byte("0000000key"), []byte("hash"))
+	stub := &rpcClientStub{
+		session: &Session{Token: &token, ExpireAt: protot.TimeToTimestamp(time.Now().Add(time.Hour))},
+	}
+	m := &mnemosyne{client: stub, cache: newSessionCache(10, time.Minute)}
+
+	for i := 0; i < 3; i++ {
+		_, err := m.Get(context.Background(), token)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, stub.calls)
+
+	ctx := metadata.NewContext(context.Background(), metadata.Pairs(TokenMetadataKey, token.Encode()))
+	for i := 0; i < 3; i++ {
+		_, err := m.FromContext(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, stub.calls)
+
+	exists, err := m.Exists(context.Background(), token)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, 1, stub.calls)
+
+	// Modification invalidates cached session.
+	_, err = m.SetValue(context.Background(), token, "key", "value")
+	require.NoError(t, err)
+	_, err = m.Get(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stub.calls)
+}
+
+func TestMnemosyne_AbandonAll_cache(t *testing.T) {
+	token := NewToken([]byte("0000000key"), []byte("hash"))
+	stub := &rpcClientStub{
+		session: &Session{Token: &token, SubjectId: "subject", ExpireAt: protot.TimeToTimestamp(time.Now().Add(time.Hour))},
+	}
+	m := &mnemosyne{client: stub, cache: newSessionCache(10, time.Minute)}
+
+	// Session retrieved while call is in progress cannot stay in the cache.
+	stub.abandonAll = func() {
+		_, err := m.Get(context.Background(), token)
+		require.NoError(t, err)
+	}
+	_, err := m.AbandonAll(context.Background(), "subject", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stub.calls)
+
+	_, err = m.Get(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stub.calls)
+}
+
+func TestMnemosyne_withoutCache(t *testing.T) {
+	token := NewToken([]byte("0000000key"), []byte("hash"))
+	stub := &rpcClientStub{session: &Session{Token: &token}}
+	m := &mnemosyne{client: stub}
+
+	for i := 0; i < 3; i++ {
+		_, err := m.Get(context.Background(), token)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, stub.calls)
+}
+
+func TestMnemosyne_FromContext(t *testing.T) {
+	token := NewToken([]byte("0000000key"), []byte("hash"))
+	stub := &rpcClientStub{session: &Session{Token: &token}}
+	m := &mnemosyne{client: stub, metadata: metadata.Pairs("client", "test")}
+
+	ses, err := m.FromContext(NewTokenContext(context.Background(), token))
+	require.NoError(t, err)
+	assert.Equal(t, stub.session, ses)
+	assert.Equal(t, []string{token.Encode()}, stub.metadata[TokenMetadataKey])
+	assert.Equal(t, []string{"test"}, stub.metadata["client"])
+
+	// Token already attached to the metadata takes precedence.
+	other := NewToken([]byte("0000000key"), []byte("other"))
+	ctx := metadata.NewContext(NewTokenContext(context.Background(), token), metadata.Pairs(TokenMetadataKey, other.Encode()))
+	_, err = m.FromContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{other.Encode()}, stub.metadata[TokenMetadataKey])
+}
+
+func TestMnemosyne_StartWithTTL(t *testing.T) {
+	stub := &rpcClientStub{session: &Session{SubjectId: "subject"}}
+	m := &mnemosyne{client: stub}
+
+	data := map[time.Duration]int64{
+		0:                       0,
+		time.Minute:             60,
+		1500 * time.Millisecond: 2,
+	}
+	for ttl, expected := range data {
+		_, err := m.StartWithTTL(context.Background(), "subject", ttl, map[string]string{"key": "value"})
+		require.NoError(t, err)
+		assert.Equal(t, expected, stub.start.Ttl, "ttl: %s", ttl)
+		assert.Equal(t, "subject", stub.start.SubjectId)
+		assert.Equal(t, map[string]string{"key": "value"}, stub.start.Bag)
+	}
+
+	_, err := m.Start(context.Background(), "subject", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stub.start.Ttl, "server default should be used")
+}
+
+func TestMnemosyne_TouchWithTTL(t *testing.T) {
+	token := NewToken([]byte("0000000key"), []byte("hash"))
+	stub := &rpcClientStub{session: &Session{Token: &token}}
+	m := &mnemosyne{client: stub}
+
+	data := map[time.Duration]int64{
+		0:                       0,
+		2 * time.Hour:           7200,
+		1500 * time.Millisecond: 2,
+	}
+	for ttl, expected := range data {
+		_, err := m.TouchWithTTL(context.Background(), token, ttl)
+		require.NoError(t, err)
+		assert.Equal(t, expected, stub.touch.Ttl, "ttl: %s", ttl)
+		assert.Equal(t, &token, stub.touch.Token)
+	}
+
+	_, err := m.Touch(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stub.touch.Ttl, "server default should be used")
+}
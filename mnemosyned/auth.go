@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/piotrkowalczuk/mnemosyne"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// authRoleAdmin can call every endpoint.
+	authRoleAdmin = "admin"
+	// authRoleService can manage single sessions, but cannot list, watch or remove sessions in bulk.
+	authRoleService = "service"
+)
+
+var (
+	authServiceEndpoints = map[string]bool{
+		"start":        true,
+		"get":          true,
+		"context":      true,
+		"exists":       true,
+		"touch":        true,
+		"set_value":    true,
+		"delete_value": true,
+		"clear":        true,
+		"abandon":      true,
+	}
+)
+
+// authorizer identifies callers by API key passed in metadata or by subject of verified client certificate (mutual TLS)
+// and decides which endpoints they are allowed to call.
+type authorizer struct {
+	// keys are indexed by SHA-256 sum, so lookup time does not depend on how much of the key matches.
+	keys     map[[sha256.Size]byte]*authCaller
+	subjects map[string]*authCaller
+}
+
+// authCaller is a single entry of authorization file, e.g.:
+//
+//	{"callers": [
+//		{"name": "frontend", "role": "service", "keys": ["secret"]},
+//		{"name": "operator", "role": "admin", "subjects": ["operator.example.com"]}
+//	]}
+type authCaller struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+	// Keys are API keys passed by the caller in mnemosyne.APIKeyMetadataKey metadata.
+	Keys []string `json:"keys"`
+	// Subjects are common names of client certificates presented by the caller.
+	Subjects []string `json:"subjects"`
+}
+
+func newAuthorizer(callers []*authCaller) (*authorizer, error) {
+	a := &authorizer{
+		keys:     make(map[[sha256.Size]byte]*authCaller),
+		subjects: make(map[string]*authCaller),
+	}
+
+	for _, c := range callers {
+		switch {
+		case c.Name == "":
+			return nil, errors.New("mnemosyned: auth caller name is missing")
+		case c.Role != authRoleAdmin && c.Role != authRoleService:
+			return nil, fmt.Errorf("mnemosyned: auth caller %s has unknown role: %s", c.Name, c.Role)
+		}
+
+		for _, key := range c.Keys {
+			if key == "" {
+				return nil, fmt.Errorf("mnemosyned: auth caller %s has empty key", c.Name)
+			}
+			sum := sha256.Sum256([]byte(key))
+			if _, ok := a.keys[sum]; ok {
+				return nil, fmt.Errorf("mnemosyned: auth caller %s has key used by another caller", c.Name)
+			}
+			a.keys[sum] = c
+		}
+		for _, subject := range c.Subjects {
+			if _, ok := a.subjects[subject]; ok {
+				return nil, fmt.Errorf("mnemosyned: auth caller %s has subject used by another caller: %s", c.Name, subject)
+			}
+			a.subjects[subject] = c
+		}
+	}
+
+	return a, nil
+}
+
+func initAuthorizer(file string) func() (*authorizer, error) {
+	return func() (*authorizer, error) {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("mnemosyned: auth file opening failure: %s", err.Error())
+		}
+		defer f.Close()
+
+		var cfg struct {
+			Callers []*authCaller `json:"callers"`
+		}
+		if err := json.NewDecoder(f).Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("mnemosyned: auth file decoding failure: %s", err.Error())
+		}
+
+		return newAuthorizer(cfg.Callers)
+	}
+}
+
+// authorize returns the caller if it is allowed to call given endpoint.
+// API key takes precedence over client certificate.
+func (a *authorizer) authorize(ctx context.Context, endpoint string) (*authCaller, error) {
+	caller := a.identify(ctx)
+	if caller == nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "mnemosyne: unknown caller")
+	}
+
+	if caller.Role != authRoleAdmin && !authServiceEndpoints[endpoint] {
+		return caller, grpc.Errorf(codes.PermissionDenied, "mnemosyne: %s is not allowed to call %s", caller.Name, endpoint)
+	}
+
+	return caller, nil
+}
+
+func (a *authorizer) identify(ctx context.Context) *authCaller {
+	if md, ok := metadata.FromContext(ctx); ok && len(md[mnemosyne.APIKeyMetadataKey]) > 0 {
+		return a.keys[sha256.Sum256([]byte(md[mnemosyne.APIKeyMetadataKey][0]))]
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return a.subjects[info.State.VerifiedChains[0][0].Subject.CommonName]
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestAuthorizer_authorize(t *testing.T) {
+	a, err := newAuthorizer([]*authCaller{
+		{Name: "frontend", Role: authRoleService, Keys: []string{"frontend-key"}},
+		{Name: "operator", Role: authRoleAdmin, Keys: []string{"operator-key"}, Subjects: []string{"operator.example.com"}},
+	})
+	require.NoError(t, err)
+
+	withKey := func(key string) context.Context {
+		return metadata.NewContext(context.Background(), metadata.Pairs(mnemosyne.APIKeyMetadataKey, key))
+	}
+	withSubject := func(subject string, verified bool) context.Context {
+		state := tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: subject}}},
+		}
+		if verified {
+			state.VerifiedChains = [][]*x509.Certificate{state.PeerCertificates}
+		}
+
+		return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	}
+
+	data := map[string]struct {
+		ctx      context.Context
+		endpoint string
+		caller   string
+		code     codes.Code
+	}{
+		"anonymous": {
+			ctx:      context.Background(),
+			endpoint: "get",
+			code:     codes.Unauthenticated,
+		},
+		"unknown-key": {
+			ctx:      withKey("unknown"),
+			endpoint: "get",
+			code:     codes.Unauthenticated,
+		},
+		"service-get": {
+			ctx:      withKey("frontend-key"),
+			endpoint: "get",
+			caller:   "frontend",
+			code:     codes.OK,
+		},
+		"service-set-value": {
+			ctx:      withKey("frontend-key"),
+			endpoint: "set_value",
+			caller:   "frontend",
+			code:     codes.OK,
+		},
+		"service-context": {
+			ctx:      withKey("frontend-key"),
+			endpoint: "context",
+			caller:   "frontend",
+			code:     codes.OK,
+		},
+		"service-touch": {
+			ctx:      withKey("frontend-key"),
+			endpoint: "touch",
+			caller:   "frontend",
+			code:     codes.OK,
+		},
+		"service-delete-value": {
+			ctx:      withKey("frontend-key"),
+			endpoint: "delete_value",
+			caller:   "frontend",
+			code:     codes.OK,
+		},
+		"service-clear": {
+			ctx:      withKey("frontend-key"),
+			endpoint: "clear",
+			caller:   "frontend",
+			code:     codes.OK,
+		},
+		"service-watch": {
+			ctx:      withKey("frontend-key"),
+			endpoint: "watch",
+			caller:   "frontend",
+			code:     codes.PermissionDenied,
+		},
+		"service-list": {
+			ctx:      withKey("frontend-key"),
+			endpoint: "list",
+			caller:   "frontend",
+			code:     codes.PermissionDenied,
+		},
+		"service-delete": {
+			ctx:      withKey("frontend-key"),
+			endpoint: "delete",
+			caller:   "frontend",
+			code:     codes.PermissionDenied,
+		},
+		"service-abandon-all": {
+			ctx:      withKey("frontend-key"),
+			endpoint: "abandon_all",
+			caller:   "frontend",
+			code:     codes.PermissionDenied,
+		},
+		"admin-delete": {
+			ctx:      withKey("operator-key"),
+			endpoint: "delete",
+			caller:   "operator",
+			code:     codes.OK,
+		},
+		"admin-certificate": {
+			ctx:      withSubject("operator.example.com", true),
+			endpoint: "list",
+			caller:   "operator",
+			code:     codes.OK,
+		},
+		"unverified-certificate": {
+			ctx:      withSubject("operator.example.com", false),
+			endpoint: "list",
+			code:     codes.Unauthenticated,
+		},
+		"unknown-certificate": {
+			ctx:      withSubject("unknown.example.com", true),
+			endpoint: "get",
+			code:     codes.Unauthenticated,
+		},
+	}
+
+	for hint, d := range data {
+		caller, err := a.authorize(d.ctx, d.endpoint)
+		assert.Equal(t, d.code, grpc.Code(err), hint)
+		if d.caller == "" {
+			assert.Nil(t, caller, hint)
+		} else if assert.NotNil(t, caller, hint) {
+			assert.Equal(t, d.caller, caller.Name, hint)
+		}
+	}
+}
+
+func TestNewAuthorizer(t *testing.T) {
+	data := map[string][]*authCaller{
+		"missing-name":       {{Role: authRoleAdmin}},
+		"unknown-role":       {{Name: "frontend", Role: "root"}},
+		"empty-key":          {{Name: "frontend", Role: authRoleService, Keys: []string{""}}},
+		"duplicated-key":     {{Name: "frontend", Role: authRoleService, Keys: []string{"key"}}, {Name: "backend", Role: authRoleService, Keys: []string{"key"}}},
+		"duplicated-subject": {{Name: "frontend", Role: authRoleService, Subjects: []string{"a"}}, {Name: "backend", Role: authRoleAdmin, Subjects: []string{"a"}}},
+	}
+
+	for hint, callers := range data {
+		_, err := newAuthorizer(callers)
+		assert.Error(t, err, hint)
+	}
+}
+
+func TestInitAuthorizer(t *testing.T) {
+	file, err := ioutil.TempFile("", "mnemosyned")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(`{"callers": [{"name": "frontend", "role": "service", "keys": ["secret"]}]}`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	a, err := initAuthorizer(file.Name())()
+	require.NoError(t, err)
+
+	caller, err := a.authorize(metadata.NewContext(context.Background(), metadata.Pairs(mnemosyne.APIKeyMetadataKey, "secret")), "start")
+	require.NoError(t, err)
+	assert.Equal(t, "frontend", caller.Name)
+
+	_, err = initAuthorizer(file.Name() + ".missing")()
+	assert.Error(t, err)
+}
@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"database/sql/driver"
 	"encoding/gob"
+	"encoding/json"
 	"errors"
+	"sort"
 )
 
 type bagpack map[string]string
 
+// bagpackKeys represents keys of the bag as JSON array, which can be searched by the database.
+type bagpackKeys bagpack
+
 // Scan satisfy sql.Scanner interface.
 func (b *bagpack) Scan(src interface{}) (err error) {
 	switch t := src.(type) {
@@ -42,9 +47,31 @@ func (bp *bagpack) Get(key string) string {
 	return (*bp)[key]
 }
 
+// Del implements Bag interface.
+func (bp *bagpack) Del(key string) {
+	delete(*bp, key)
+}
+
 // Has implements Bag interface.
 func (bp *bagpack) Has(key string) bool {
 	_, ok := (*bp)[key]
 
 	return ok
 }
+
+// Value satisfy driver.Valuer interface.
+func (bk bagpackKeys) Value() (driver.Value, error) {
+	keys := make([]string, 0, len(bk))
+	for key := range bk {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf, err := json.Marshal(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// Textual representation, otherwise it would be sent as bytea.
+	return string(buf), nil
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/piotrkowalczuk/mnemosyne"
+)
+
+// cachedStorage is a read-through Storage decorator that keeps recently retrieved sessions in bounded LRU cache.
+// Missing sessions are cached as well, so repeated lookups of invalid tokens do not reach underlying storage.
+// Sessions are copied when stored and retrieved, so callers cannot modify cached ones.
+type cachedStorage struct {
+	Storage
+	sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	lru     *list.List
+	// version changes on every invalidation, lookup that started before it cannot populate the cache.
+	version uint64
+	monitor monitoringCache
+	done    chan struct{}
+}
+
+type cacheEntry struct {
+	key string
+	// session is nil if session does not exist.
+	session  *mnemosyne.Session
+	cachedAt time.Time
+}
+
+func newCachedStorage(storage Storage, size int, ttl time.Duration, monitor monitoringCache) *cachedStorage {
+	return &cachedStorage{
+		Storage: storage,
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element, size),
+		lru:     list.New(),
+		monitor: monitor,
+		done:    make(chan struct{}),
+	}
+}
+
+func initCachedStorage(storage Storage, size int, ttl time.Duration, monitor monitoringCache) func() (Storage, error) {
+	return func() (Storage, error) {
+		switch {
+		case size <= 0:
+			return nil, errors.New("mnemosyned: cache size needs to be higher than 0")
+		case ttl <= 0:
+			return nil, errors.New("mnemosyned: cache ttl needs to be higher than 0")
+		}
+
+		return newCachedStorage(storage, size, ttl, monitor), nil
+	}
+}
+
+// Get implements Storage interface.
+func (cs *cachedStorage) Get(token *mnemosyne.Token) (*mnemosyne.Session, error) {
+	key := token.Encode()
+
+	if entry, ok := cs.lookup(key); ok {
+		if entry.session == nil {
+			return nil, errSessionNotFound
+		}
+		return proto.Clone(entry.session).(*mnemosyne.Session), nil
+	}
+
+	version := cs.current()
+	ses, err := cs.Storage.Get(token)
+	switch err {
+	case nil:
+		cs.store(version, key, ses)
+	case errSessionNotFound:
+		cs.store(version, key, nil)
+	}
+
+	return ses, err
+}
+
+// Exists implements Storage interface.
+func (cs *cachedStorage) Exists(token *mnemosyne.Token) (bool, error) {
+	key := token.Encode()
+
+	if entry, ok := cs.lookup(key); ok {
+		return entry.session != nil, nil
+	}
+
+	// Existence check does not retrieve the session, only negative result can be cached.
+	version := cs.current()
+	exists, err := cs.Storage.Exists(token)
+	if err == nil && !exists {
+		cs.store(version, key, nil)
+	}
+
+	return exists, err
+}
+
+// Start implements Storage interface.
+func (cs *cachedStorage) Start(subjectID string, bag map[string]string, ttl time.Duration) (*mnemosyne.Session, error) {
+	ses, err := cs.Storage.Start(subjectID, bag, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	cs.invalidate(ses)
+
+	return ses, nil
+}
+
+// Abandon implements Storage interface.
+func (cs *cachedStorage) Abandon(token *mnemosyne.Token) (*mnemosyne.Session, error) {
+	defer cs.invalidateToken(token)
+
+	return cs.Storage.Abandon(token)
+}
+
+// AbandonAll implements Storage interface.
+func (cs *cachedStorage) AbandonAll(subjectID string, except *mnemosyne.Token) ([]*mnemosyne.Session, error) {
+	abandoned, err := cs.Storage.AbandonAll(subjectID, except)
+	cs.invalidate(abandoned...)
+
+	return abandoned, err
+}
+
+// Touch implements Storage interface.
+func (cs *cachedStorage) Touch(token *mnemosyne.Token, ttl time.Duration) (*mnemosyne.Session, error) {
+	ses, err := cs.Storage.Touch(token, ttl)
+	cs.refresh(token, ses, err)
+
+	return ses, err
+}
+
+// SetValue implements Storage interface.
+func (cs *cachedStorage) SetValue(token *mnemosyne.Token, key, value string) (*mnemosyne.Session, error) {
+	ses, err := cs.Storage.SetValue(token, key, value)
+	cs.refresh(token, ses, err)
+
+	return ses, err
+}
+
+// DeleteValue implements Storage interface.
+func (cs *cachedStorage) DeleteValue(token *mnemosyne.Token, key string) (*mnemosyne.Session, error) {
+	ses, err := cs.Storage.DeleteValue(token, key)
+	cs.refresh(token, ses, err)
+
+	return ses, err
+}
+
+// Clear implements Storage interface.
+func (cs *cachedStorage) Clear(token *mnemosyne.Token) (*mnemosyne.Session, error) {
+	ses, err := cs.Storage.Clear(token)
+	cs.refresh(token, ses, err)
+
+	return ses, err
+}
+
+// Delete implements Storage interface.
+func (cs *cachedStorage) Delete(token *mnemosyne.Token, expiredAtFrom, expiredAtTo *time.Time) ([]*mnemosyne.Session, error) {
+	deleted, err := cs.Storage.Delete(token, expiredAtFrom, expiredAtTo)
+	cs.invalidate(deleted...)
+
+	return deleted, err
+}
+
+// Purge implements Storage interface.
+func (cs *cachedStorage) Purge(limit int64) ([]*mnemosyne.Session, error) {
+	purged, err := cs.Storage.Purge(limit)
+	cs.invalidate(purged...)
+
+	return purged, err
+}
+
+// TearDown implements Storage interface.
+func (cs *cachedStorage) TearDown() error {
+	cs.reset()
+
+	return cs.Storage.TearDown()
+}
+
+// start invalidates cached sessions on every event, including those published by other daemons.
+// If watcher gets disconnected, whole cache is dropped and events are watched again.
+// Once event bus is closed on shutdown, cache is dropped and it is no longer invalidated by other daemons.
+func (cs *cachedStorage) start(events *eventBus) {
+	go func() {
+		ew := events.watch("")
+		for {
+			select {
+			case event, ok := <-ew.events:
+				if !ok {
+					cs.reset()
+					if ew.err == errEventBusClosed {
+						<-cs.done
+						return
+					}
+					ew = events.watch("")
+					continue
+				}
+
+				cs.invalidate(event.Session)
+			case <-cs.done:
+				events.unwatch(ew)
+				return
+			}
+		}
+	}()
+}
+
+// stop terminates invalidation loop.
+func (cs *cachedStorage) stop() {
+	close(cs.done)
+}
+
+func (cs *cachedStorage) lookup(key string) (*cacheEntry, bool) {
+	cs.Lock()
+	defer cs.Unlock()
+
+	el, ok := cs.entries[key]
+	if !ok {
+		cs.monitor.misses.Add(1)
+		return nil, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if entry.stale(cs.ttl) {
+		cs.remove(el)
+		cs.monitor.misses.Add(1)
+		return nil, false
+	}
+
+	cs.lru.MoveToFront(el)
+	cs.monitor.hits.Add(1)
+
+	return entry, true
+}
+
+func (cs *cachedStorage) current() uint64 {
+	cs.Lock()
+	defer cs.Unlock()
+
+	return cs.version
+}
+
+// store puts session into the cache, unless anything was invalidated since given version.
+func (cs *cachedStorage) store(version uint64, key string, ses *mnemosyne.Session) {
+	cs.Lock()
+	defer cs.Unlock()
+
+	if version != cs.version {
+		return
+	}
+
+	cs.put(key, ses)
+}
+
+// refresh replaces cached session by the one returned by modifying operation.
+func (cs *cachedStorage) refresh(token *mnemosyne.Token, ses *mnemosyne.Session, err error) {
+	cs.Lock()
+	defer cs.Unlock()
+
+	cs.version++
+	if err != nil {
+		if el, ok := cs.entries[token.Encode()]; ok {
+			cs.remove(el)
+		}
+		return
+	}
+
+	cs.put(token.Encode(), ses)
+}
+
+func (cs *cachedStorage) invalidate(sessions ...*mnemosyne.Session) {
+	for _, ses := range sessions {
+		if ses != nil && ses.Token != nil {
+			cs.invalidateToken(ses.Token)
+		}
+	}
+}
+
+func (cs *cachedStorage) invalidateToken(token *mnemosyne.Token) {
+	cs.Lock()
+	defer cs.Unlock()
+
+	cs.version++
+	if el, ok := cs.entries[token.Encode()]; ok {
+		cs.remove(el)
+	}
+}
+
+func (cs *cachedStorage) reset() {
+	cs.Lock()
+	defer cs.Unlock()
+
+	cs.version++
+	cs.entries = make(map[string]*list.Element, cs.size)
+	cs.lru.Init()
+}
+
+func (cs *cachedStorage) put(key string, ses *mnemosyne.Session) {
+	if el, ok := cs.entries[key]; ok {
+		cs.remove(el)
+	}
+	if ses != nil {
+		ses = proto.Clone(ses).(*mnemosyne.Session)
+	}
+
+	cs.entries[key] = cs.lru.PushFront(&cacheEntry{
+		key:      key,
+		session:  ses,
+		cachedAt: time.Now(),
+	})
+
+	for cs.lru.Len() > cs.size {
+		cs.remove(cs.lru.Back())
+	}
+}
+
+func (cs *cachedStorage) remove(el *list.Element) {
+	delete(cs.entries, el.Value.(*cacheEntry).key)
+	cs.lru.Remove(el)
+}
+
+// stale returns true if entry was cached longer than given ttl or cached session already expired.
+func (ce *cacheEntry) stale(ttl time.Duration) bool {
+	now := time.Now()
+	if now.Sub(ce.cachedAt) > ttl {
+		return true
+	}
+
+	return ce.session != nil && !ce.session.ExpireAt.Time().After(now)
+}
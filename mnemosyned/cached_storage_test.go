@@ -0,0 +1,250 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/piotrkowalczuk/protot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	cachedStore = newCachedStorage(newMemoryStorage(memoryStorageShards), 100, time.Minute, monitoringCache{
+		hits:   discard.NewCounter("hits"),
+		misses: discard.NewCounter("misses"),
+	})
+)
+
+func TestCachedStorage_Start(t *testing.T) {
+	testStorage_Start(t, cachedStore)
+}
+
+func TestCachedStorage_Get(t *testing.T) {
+	testStorage_Get(t, cachedStore)
+}
+
+func TestCachedStorage_List(t *testing.T) {
+	testStorage_List(t, cachedStore)
+}
+
+func TestCachedStorage_Exists(t *testing.T) {
+	testStorage_Exists(t, cachedStore)
+}
+
+func TestCachedStorage_Abandon(t *testing.T) {
+	testStorage_Abandon(t, cachedStore)
+}
+
+func TestCachedStorage_AbandonAll(t *testing.T) {
+	testStorage_AbandonAll(t, cachedStore)
+}
+
+func TestCachedStorage_Touch(t *testing.T) {
+	testStorage_Touch(t, cachedStore)
+}
+
+func TestCachedStorage_SetValue(t *testing.T) {
+	testStorage_SetValue(t, cachedStore)
+}
+
+func TestCachedStorage_DeleteValue(t *testing.T) {
+	testStorage_DeleteValue(t, cachedStore)
+}
+
+func TestCachedStorage_Clear(t *testing.T) {
+	testStorage_Clear(t, cachedStore)
+}
+
+func TestCachedStorage_Delete(t *testing.T) {
+	testStorage_Delete(t, cachedStore)
+}
+
+func TestCachedStorage_Purge(t *testing.T) {
+	testStorage_Purge(t, cachedStore)
+}
+
+func TestCachedStorage_Count(t *testing.T) {
+	testStorage_Count(t, cachedStore)
+}
+
+func TestCachedStorage_readThrough(t *testing.T) {
+	token := mnemosyne.NewToken([]byte("key"), []byte("hash"))
+	missing := mnemosyne.NewToken([]byte("key"), []byte("missing"))
+	ses := &mnemosyne.Session{Token: &token, SubjectId: "subject", ExpireAt: protot.TimeToTimestamp(time.Now().Add(time.Hour))}
+
+	storage := &storageMock{}
+	storage.On("Get", &token).Return(ses, nil).Once()
+	storage.On("Get", &missing).Return(nil, errSessionNotFound).Once()
+
+	cs := newTestCachedStorage(storage, 10, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		got, err := cs.Get(&token)
+		require.NoError(t, err)
+		assert.Equal(t, ses, got)
+
+		_, err = cs.Get(&missing)
+		assert.Equal(t, errSessionNotFound, err)
+
+		exists, err := cs.Exists(&missing)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	}
+
+	storage.AssertExpectations(t)
+}
+
+func TestCachedStorage_invalidation(t *testing.T) {
+	token := mnemosyne.NewToken([]byte("key"), []byte("hash"))
+	ses := &mnemosyne.Session{Token: &token, SubjectId: "subject", ExpireAt: protot.TimeToTimestamp(time.Now().Add(time.Hour))}
+	modified := &mnemosyne.Session{Token: &token, SubjectId: "subject", Bag: map[string]string{"key": "value"}, ExpireAt: ses.ExpireAt}
+
+	storage := &storageMock{}
+	storage.On("Get", &token).Return(ses, nil).Twice()
+	storage.On("SetValue", &token, "key", "value").Return(modified, nil).Once()
+	storage.On("Abandon", &token).Return(modified, nil).Once()
+
+	cs := newTestCachedStorage(storage, 10, time.Minute)
+
+	_, err := cs.Get(&token)
+	require.NoError(t, err)
+
+	// Modified session replaces cached one.
+	_, err = cs.SetValue(&token, "key", "value")
+	require.NoError(t, err)
+	got, err := cs.Get(&token)
+	require.NoError(t, err)
+	assert.Equal(t, modified, got)
+
+	_, err = cs.Abandon(&token)
+	require.NoError(t, err)
+	got, err = cs.Get(&token)
+	require.NoError(t, err)
+	assert.Equal(t, ses, got)
+
+	storage.AssertExpectations(t)
+}
+
+func TestCachedStorage_eviction(t *testing.T) {
+	expireAt := protot.TimeToTimestamp(time.Now().Add(time.Hour))
+	tokens := make([]mnemosyne.Token, 0, 3)
+	storage := &storageMock{}
+	for _, hash := range []string{"1", "2", "3"} {
+		token := mnemosyne.NewToken([]byte("key"), []byte(hash))
+		tokens = append(tokens, token)
+		storage.On("Get", &token).Return(&mnemosyne.Session{Token: &token, ExpireAt: expireAt}, nil)
+	}
+
+	cs := newTestCachedStorage(storage, 2, time.Minute)
+
+	for _, i := range []int{0, 1, 0, 2, 0} {
+		_, err := cs.Get(&tokens[i])
+		require.NoError(t, err)
+	}
+
+	// Second token was the least recently used one.
+	assert.Len(t, cs.entries, 2)
+	assert.NotContains(t, cs.entries, tokens[1].Encode())
+	storage.AssertNumberOfCalls(t, "Get", 3)
+}
+
+func TestCachedStorage_ttl(t *testing.T) {
+	token := mnemosyne.NewToken([]byte("key"), []byte("hash"))
+	ses := &mnemosyne.Session{Token: &token, ExpireAt: protot.TimeToTimestamp(time.Now().Add(time.Hour))}
+
+	storage := &storageMock{}
+	storage.On("Get", &token).Return(ses, nil).Twice()
+
+	cs := newTestCachedStorage(storage, 10, time.Millisecond)
+
+	_, err := cs.Get(&token)
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	_, err = cs.Get(&token)
+	require.NoError(t, err)
+
+	storage.AssertExpectations(t)
+}
+
+func TestCachedStorage_start(t *testing.T) {
+	token := mnemosyne.NewToken([]byte("key"), []byte("hash"))
+	ses := &mnemosyne.Session{Token: &token, ExpireAt: protot.TimeToTimestamp(time.Now().Add(time.Hour))}
+
+	storage := &storageMock{}
+	storage.On("Get", mock.AnythingOfType("*mnemosyne.Token")).Return(ses, nil).Once()
+
+	events := newEventBus(10)
+	cs := newTestCachedStorage(storage, 10, time.Minute)
+	cs.start(events)
+	defer cs.stop()
+
+	_, err := cs.Get(&token)
+	require.NoError(t, err)
+
+	// Event published by other daemon.
+	for cs.current() == 0 {
+		events.publish(mnemosyne.Event_ABANDONED, ses)
+		time.Sleep(time.Millisecond)
+	}
+
+	cs.Lock()
+	assert.Len(t, cs.entries, 0)
+	cs.Unlock()
+}
+
+func TestCachedStorage_start_closed(t *testing.T) {
+	events := newEventBus(10)
+	cs := newTestCachedStorage(&storageMock{}, 10, time.Minute)
+	cs.start(events)
+	defer cs.stop()
+
+	for {
+		events.RLock()
+		n := len(events.watchers)
+		events.RUnlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	events.close()
+
+	// Cache is dropped once, closed event bus is not watched again.
+	for cs.current() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, uint64(1), cs.current())
+}
+
+func newTestCachedStorage(storage Storage, size int, ttl time.Duration) *cachedStorage {
+	return newCachedStorage(storage, size, ttl, monitoringCache{
+		hits:   discard.NewCounter("hits"),
+		misses: discard.NewCounter("misses"),
+	})
+}
+
+func TestCachedStorage_isolation(t *testing.T) {
+	token := mnemosyne.NewToken([]byte("key"), []byte("hash"))
+	ses := &mnemosyne.Session{Token: &token, SubjectId: "subject", Bag: map[string]string{"key": "value"}, ExpireAt: protot.TimeToTimestamp(time.Now().Add(time.Hour))}
+
+	storage := &storageMock{}
+	storage.On("Get", &token).Return(ses, nil).Once()
+
+	cs := newTestCachedStorage(storage, 10, time.Minute)
+
+	got, err := cs.Get(&token)
+	require.NoError(t, err)
+	got.Bag["key"] = "modified by first caller"
+	ses.Bag["key"] = "modified by underlying storage"
+
+	got, err = cs.Get(&token)
+	require.NoError(t, err)
+	assert.Equal(t, "value", got.Bag["key"])
+
+	storage.AssertExpectations(t)
+}
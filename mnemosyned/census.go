@@ -0,0 +1,66 @@
+package main
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+	"github.com/piotrkowalczuk/sklog"
+)
+
+// census periodically counts sessions that did not expire yet and reports the number as a gauge.
+type census struct {
+	logger   log.Logger
+	storage  Storage
+	active   metrics.Gauge
+	interval time.Duration
+	done     chan struct{}
+}
+
+func newCensus(logger log.Logger, storage Storage, active metrics.Gauge, interval time.Duration) *census {
+	return &census{
+		logger:   log.NewContext(logger).With("interval", interval),
+		storage:  storage,
+		active:   active,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// start counts sessions right away and then runs census loop in separate goroutine.
+func (c *census) start() {
+	c.count()
+	go c.run()
+
+	sklog.Info(c.logger, "session census has been started")
+}
+
+// stop terminates census loop.
+func (c *census) stop() {
+	close(c.done)
+}
+
+func (c *census) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.count()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// count refreshes the gauge, previous value is kept if storage fails.
+func (c *census) count() {
+	count, err := c.storage.Count()
+	if err != nil {
+		sklog.Error(c.logger, err)
+		return
+	}
+
+	c.active.Set(float64(count))
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCensus_count(t *testing.T) {
+	storage := &storageMock{}
+	storage.On("Count").Return(int64(5), nil).Once()
+	storage.On("Count").Return(int64(0), errors.New("fake storage error")).Once()
+
+	active := &censusGauge{}
+	c := newCensus(log.NewNopLogger(), storage, active, time.Minute)
+
+	c.count()
+	assert.Equal(t, float64(5), active.Get())
+
+	c.count()
+	assert.Equal(t, float64(5), active.Get(), "previous value should be kept if storage fails")
+
+	storage.AssertExpectations(t)
+}
+
+type censusGauge struct {
+	value float64
+}
+
+func (cg *censusGauge) Name() string                     { return "sessions_active" }
+func (cg *censusGauge) With(metrics.Field) metrics.Gauge { return cg }
+func (cg *censusGauge) Set(value float64)                { cg.value = value }
+func (cg *censusGauge) Add(delta float64)                { cg.value += delta }
+func (cg *censusGauge) Get() float64                     { return cg.value }
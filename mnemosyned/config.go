@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 )
 
 const VERSION="0.0.1"
@@ -13,19 +14,69 @@ type configuration struct {
 	port      int
 	namespace string
 	subsystem string
-	logger    struct {
+	session   struct {
+		ttl    time.Duration
+		ttlMax time.Duration
+		touch  bool
+	}
+	logger struct {
 		adapter string
 		format  string
 		level   int
 	}
 	monitoring struct {
-		engine string
+		engine   string
+		sessions time.Duration
+	}
+	cache struct {
+		size int
+		ttl  time.Duration
+	}
+	watch struct {
+		buffer int
+	}
+	gateway struct {
+		port int
+	}
+	health struct {
+		interval time.Duration
+		drain    time.Duration
+		grace    time.Duration
+	}
+	debug struct {
+		port      int
+		profiling bool
+	}
+	auth struct {
+		file string
+	}
+	tls struct {
+		enabled  bool
+		certFile string
+		keyFile  string
+		caFile   string
+		reload   time.Duration
+	}
+	reaper struct {
+		interval time.Duration
+		batch    int64
 	}
 	storage struct {
-		engine   string
+		engine string
+		memory struct {
+			shards int
+		}
 		postgres struct {
 			connectionString string
-			tableName        string
+			schema           string
+			table            string
+			notify           bool
+		}
+		redis struct {
+			address  string
+			password string
+			database int
+			prefix   string
 		}
 	}
 }
@@ -39,13 +90,41 @@ func (c *configuration) init() {
 	flag.IntVar(&c.port, "port", 8080, "port")
 	flag.StringVar(&c.namespace, "namespace", "", "namespace")
 	flag.StringVar(&c.subsystem, "subsystem", "mnemosyne", "subsystem")
+	flag.DurationVar(&c.session.ttl, "ttl", 30*time.Minute, "default session lifetime")
+	flag.DurationVar(&c.session.ttlMax, "ttl.max", 24*time.Hour, "maximum session lifetime that can be requested")
+	flag.BoolVar(&c.session.touch, "ttl.touch", false, "if true, session lifetime is extended on every get and context call")
 	flag.StringVar(&c.logger.adapter, "l.adapter", loggerAdapterStdOut, "logger adapter")
 	flag.StringVar(&c.logger.format, "l.format", loggerFormatJSON, "logger format")
 	flag.IntVar(&c.logger.level, "l.level", 6, "logger level")
+	flag.DurationVar(&c.reaper.interval, "reaper.interval", time.Minute, "how often expired sessions are purged, 0 disables reaper")
+	flag.Int64Var(&c.reaper.batch, "reaper.batch", 1000, "maximum number of expired sessions purged at once")
+	flag.IntVar(&c.cache.size, "cache.size", 0, "maximum number of sessions cached in memory, 0 disables cache")
+	flag.DurationVar(&c.cache.ttl, "cache.ttl", 10*time.Second, "how long session can be served from the cache")
+	flag.IntVar(&c.watch.buffer, "watch.buffer", 100, "number of session events buffered per watcher before it gets disconnected, 0 disables watch")
+	flag.IntVar(&c.gateway.port, "gateway.port", 0, "port of HTTP/JSON gateway, 0 disables gateway")
+	flag.DurationVar(&c.health.interval, "health.interval", 10*time.Second, "how often storage is pinged to determine if daemon is ready to serve requests")
+	flag.DurationVar(&c.health.drain, "health.drain", 5*time.Second, "how long daemon is reported as not serving before it stops accepting new calls on shutdown")
+	flag.DurationVar(&c.health.grace, "health.grace", 10*time.Second, "how long in-flight calls are waited for on shutdown before their connections are closed")
+	flag.IntVar(&c.debug.port, "debug.port", 8081, "port of debug server that exposes prometheus metrics, 0 disables debug server")
+	flag.BoolVar(&c.debug.profiling, "debug.pprof", false, "if true, runtime profiling data is exposed by debug server under /debug/pprof")
+	flag.StringVar(&c.auth.file, "auth.file", "", "path to json file that defines callers and their roles, if empty every caller can call every endpoint")
+	flag.BoolVar(&c.tls.enabled, "tls", false, "if true, rpc api and http gateway are served over tls")
+	flag.StringVar(&c.tls.certFile, "tls.cert", "", "path to tls certificate file")
+	flag.StringVar(&c.tls.keyFile, "tls.key", "", "path to tls key file")
+	flag.StringVar(&c.tls.caFile, "tls.ca", "", "path to certificate authority file, if provided clients are required to present certificate signed by it")
+	flag.DurationVar(&c.tls.reload, "tls.reload", 30*time.Second, "how often tls files are checked for changes, 0 disables reloading")
 	flag.StringVar(&c.monitoring.engine, "m.engine", monitoringEnginePrometheus, "monitoring engine")
-	flag.StringVar(&c.storage.engine, "s.engine", storageEnginePostgres, "storage engine") // TODO: change to in memory when implemented
+	flag.DurationVar(&c.monitoring.sessions, "m.sessions", 30*time.Second, "how often number of active sessions is refreshed, 0 disables refreshing")
+	flag.StringVar(&c.storage.engine, "s.engine", storageEnginePostgres, "storage engine")
+	flag.IntVar(&c.storage.memory.shards, "sm.shards", memoryStorageShards, "storage in memory number of shards")
 	flag.StringVar(&c.storage.postgres.connectionString, "sp.connectionstring", "postgres://localhost:5432?sslmode=disable", "storage postgres connection string")
-	flag.StringVar(&c.storage.postgres.tableName, "sp.tablename", "mnemosyne_session", "storage postgres table name")
+	flag.StringVar(&c.storage.postgres.schema, "sp.schema", "mnemosyne", "storage postgres schema name")
+	flag.StringVar(&c.storage.postgres.table, "sp.tablename", "session", "storage postgres table name")
+	flag.BoolVar(&c.storage.postgres.notify, "sp.notify", true, "if true, session events are propagated between daemons connected to the same table using postgres notifications")
+	flag.StringVar(&c.storage.redis.address, "sr.address", "127.0.0.1:6379", "storage redis address")
+	flag.StringVar(&c.storage.redis.password, "sr.password", "", "storage redis password")
+	flag.IntVar(&c.storage.redis.database, "sr.database", 0, "storage redis database")
+	flag.StringVar(&c.storage.redis.prefix, "sr.prefix", "mnemosyne", "storage redis key prefix")
 }
 
 func (c *configuration) parse() {
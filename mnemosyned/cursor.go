@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/piotrkowalczuk/mnemosyne"
+)
+
+// cursor points at the last session of previously returned page.
+// Sessions are ordered by expiration time and token, pair of both is unique, which makes the order stable.
+type cursor struct {
+	expireAt time.Time
+	token    mnemosyne.Token
+}
+
+func newCursor(ses *mnemosyne.Session) *cursor {
+	return &cursor{
+		expireAt: ses.ExpireAt.Time(),
+		token:    *ses.Token,
+	}
+}
+
+// decodeCursor parses page token produced by encode method.
+// Empty page token points at the very beginning of the list, so nil cursor is returned.
+func decodeCursor(pageToken string) (*cursor, error) {
+	if pageToken == "" {
+		return nil, nil
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(pageToken)
+	if err != nil {
+		return nil, mnemosyne.ErrInvalidPageToken
+	}
+
+	sep := bytes.IndexByte(buf, ':')
+	if sep < 0 {
+		return nil, mnemosyne.ErrInvalidPageToken
+	}
+
+	nsec, err := strconv.ParseInt(string(buf[:sep]), 10, 64)
+	if err != nil {
+		return nil, mnemosyne.ErrInvalidPageToken
+	}
+
+	token := mnemosyne.DecodeToken(buf[sep+1:])
+	if token.IsEmpty() {
+		return nil, mnemosyne.ErrInvalidPageToken
+	}
+
+	return &cursor{
+		expireAt: time.Unix(0, nsec),
+		token:    token,
+	}, nil
+}
+
+// encode returns opaque, url safe representation of the cursor.
+func (c *cursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString(
+		append([]byte(strconv.FormatInt(c.expireAt.UnixNano(), 10)+":"), c.token.Bytes()...),
+	)
+}
+
+// before reports whether session identified by given expiration time and token comes before or at the cursor.
+func (c *cursor) before(expireAt time.Time, token mnemosyne.Token) bool {
+	if !expireAt.Equal(c.expireAt) {
+		return expireAt.Before(c.expireAt)
+	}
+
+	return token.Encode() <= c.token.Encode()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/piotrkowalczuk/protot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	token := mnemosyne.NewToken([]byte("key"), []byte("hash"))
+	expireAt := time.Unix(1470000000, 123456789)
+
+	c := newCursor(&mnemosyne.Session{Token: &token, ExpireAt: protot.TimeToTimestamp(expireAt)})
+
+	got, err := decodeCursor(c.encode())
+	require.NoError(t, err)
+	assert.True(t, expireAt.Equal(got.expireAt))
+	assert.Equal(t, token.Encode(), got.token.Encode())
+
+	later := mnemosyne.NewToken([]byte("key"), []byte("later"))
+	assert.True(t, got.before(expireAt, token))
+	assert.True(t, got.before(expireAt.Add(-time.Microsecond), later))
+	assert.False(t, got.before(expireAt, later))
+	assert.False(t, got.before(expireAt.Add(time.Microsecond), token))
+}
+
+func TestDecodeCursor(t *testing.T) {
+	got, err := decodeCursor("")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	for _, pageToken := range []string{"!!!", "MTIz", "YWJjOnRva2Vu", "MTIzOg"} {
+		_, err := decodeCursor(pageToken)
+		assert.Equal(t, mnemosyne.ErrInvalidPageToken, err, "page token: %s", pageToken)
+	}
+}
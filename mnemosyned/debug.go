@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/piotrkowalczuk/sklog"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/net/context"
+)
+
+const (
+	// debugServerShutdownTimeout is how long in-flight requests (e.g. profiles being recorded) can take during shutdown.
+	debugServerShutdownTimeout = 5 * time.Second
+)
+
+// debugServer exposes prometheus metrics, health probes and optionally runtime profiling data over HTTP.
+type debugServer struct {
+	logger   log.Logger
+	listener net.Listener
+	server   *http.Server
+}
+
+func newDebugServer(address string, profiling bool, health *healthChecker, logger log.Logger) (*debugServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	if health != nil {
+		mux.HandleFunc("/healthz", health.live)
+		mux.HandleFunc("/readyz", health.ready)
+	}
+	if profiling {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
+	return &debugServer{
+		logger:   log.NewContext(logger).With("address", listener.Addr().String(), "profiling", profiling),
+		listener: listener,
+		server:   &http.Server{Handler: mux},
+	}, nil
+}
+
+// start serves requests in separate goroutine.
+func (ds *debugServer) start() {
+	go func() {
+		if err := ds.server.Serve(ds.listener); err != nil && err != http.ErrServerClosed {
+			sklog.Error(ds.logger, err)
+		}
+	}()
+
+	sklog.Info(ds.logger, "debug server is running")
+}
+
+// stop closes the listener and waits for in-flight requests to finish.
+func (ds *debugServer) stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), debugServerShutdownTimeout)
+	defer cancel()
+
+	return ds.server.Shutdown(ctx)
+}
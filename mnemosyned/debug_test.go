@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugServer(t *testing.T) {
+	data := map[string]struct {
+		profiling bool
+		status    map[string]int
+	}{
+		"without-profiling": {
+			status: map[string]int{
+				"/metrics":      http.StatusOK,
+				"/healthz":      http.StatusOK,
+				"/readyz":       http.StatusOK,
+				"/debug/pprof/": http.StatusNotFound,
+			},
+		},
+		"with-profiling": {
+			profiling: true,
+			status: map[string]int{
+				"/metrics":             http.StatusOK,
+				"/debug/pprof/":        http.StatusOK,
+				"/debug/pprof/cmdline": http.StatusOK,
+			},
+		},
+	}
+
+	// Without keep-alives, no connection is left open (or dialed in advance) to delay server shutdown.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	for hint, d := range data {
+		storage := &storageMock{}
+		storage.On("Ping").Return(nil)
+
+		health := newHealthChecker(log.NewNopLogger(), storage, time.Minute)
+		health.check()
+
+		ds, err := newDebugServer("127.0.0.1:0", d.profiling, health, log.NewNopLogger())
+		require.NoError(t, err, hint)
+		ds.start()
+
+		address := "http://" + ds.listener.Addr().String()
+		for path, status := range d.status {
+			res, err := client.Get(address + path)
+			require.NoError(t, err, hint)
+			res.Body.Close()
+
+			assert.Equal(t, status, res.StatusCode, "%s: %s", hint, path)
+		}
+
+		require.NoError(t, ds.stop(), hint)
+
+		_, err = client.Get(address + "/metrics")
+		assert.Error(t, err, "%s: server should not accept requests after stop", hint)
+	}
+}
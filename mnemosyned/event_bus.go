@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/piotrkowalczuk/mnemosyne"
+)
+
+const (
+	// eventBusBuffer is the number of events buffered per watcher if watch calls are disabled
+	// and events are consumed only by the daemon itself.
+	eventBusBuffer = 100
+)
+
+var (
+	errWatcherTooSlow = errors.New("mnemosyned: watcher has been disconnected, it was not able to keep up with session events")
+	errEventBusClosed = errors.New("mnemosyned: watcher has been disconnected, daemon is shutting down")
+)
+
+// eventBus distributes session lifecycle events between watchers.
+// Publishing never blocks, watcher that cannot keep up is disconnected by closing its channel,
+// otherwise it would silently miss events.
+type eventBus struct {
+	sync.RWMutex
+	buffer   int
+	closed   bool
+	watchers map[*eventWatcher]struct{}
+}
+
+type eventWatcher struct {
+	subjectID string
+	events    chan *mnemosyne.Event
+	// err explains why watcher has been disconnected, it can be read once events channel is closed.
+	err error
+}
+
+func newEventBus(buffer int) *eventBus {
+	return &eventBus{
+		buffer:   buffer,
+		watchers: make(map[*eventWatcher]struct{}),
+	}
+}
+
+// watch registers new watcher, if subject id is provided only events of sessions of given subject are delivered.
+func (eb *eventBus) watch(subjectID string) *eventWatcher {
+	ew := &eventWatcher{
+		subjectID: subjectID,
+		events:    make(chan *mnemosyne.Event, eb.buffer),
+	}
+
+	eb.Lock()
+	defer eb.Unlock()
+
+	if eb.closed {
+		ew.err = errEventBusClosed
+		close(ew.events)
+
+		return ew
+	}
+	eb.watchers[ew] = struct{}{}
+
+	return ew
+}
+
+// unwatch deregisters given watcher, it is safe to call it for already disconnected one.
+func (eb *eventBus) unwatch(ew *eventWatcher) {
+	eb.disconnect(ew, nil)
+}
+
+func (eb *eventBus) disconnect(ew *eventWatcher, err error) {
+	eb.Lock()
+	defer eb.Unlock()
+
+	if _, ok := eb.watchers[ew]; ok {
+		delete(eb.watchers, ew)
+		ew.err = err
+		close(ew.events)
+	}
+}
+
+// close disconnects all watchers, so streams that deliver events to them can finish.
+// Watchers registered afterwards are disconnected immediately.
+func (eb *eventBus) close() {
+	if eb == nil {
+		return
+	}
+
+	eb.Lock()
+	defer eb.Unlock()
+
+	eb.closed = true
+	for ew := range eb.watchers {
+		delete(eb.watchers, ew)
+		ew.err = errEventBusClosed
+		close(ew.events)
+	}
+}
+
+// publish sends event of given type for every session to all interested watchers.
+func (eb *eventBus) publish(typ mnemosyne.Event_Type, sessions ...*mnemosyne.Session) {
+	if eb == nil || len(sessions) == 0 {
+		return
+	}
+
+	var slow []*eventWatcher
+
+	eb.RLock()
+	for ew := range eb.watchers {
+		if !ew.notify(typ, sessions) {
+			slow = append(slow, ew)
+		}
+	}
+	eb.RUnlock()
+
+	for _, ew := range slow {
+		eb.disconnect(ew, errWatcherTooSlow)
+	}
+}
+
+// notify returns false if watcher buffer is full.
+func (ew *eventWatcher) notify(typ mnemosyne.Event_Type, sessions []*mnemosyne.Session) bool {
+	for _, ses := range sessions {
+		if ew.subjectID != "" && ew.subjectID != ses.SubjectId {
+			continue
+		}
+
+		select {
+		case ew.events <- &mnemosyne.Event{Type: typ, Session: ses}:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
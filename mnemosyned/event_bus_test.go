@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_publish(t *testing.T) {
+	eb := newEventBus(10)
+	all := eb.watch("")
+	subject := eb.watch("subject-1")
+
+	eb.publish(mnemosyne.Event_CREATED, &mnemosyne.Session{SubjectId: "subject-1"}, &mnemosyne.Session{SubjectId: "subject-2"})
+
+	assert.Len(t, all.events, 2)
+	if assert.Len(t, subject.events, 1) {
+		event := <-subject.events
+		assert.Equal(t, mnemosyne.Event_CREATED, event.Type)
+		assert.Equal(t, "subject-1", event.Session.SubjectId)
+	}
+
+	eb.unwatch(subject)
+	eb.unwatch(subject)
+	eb.publish(mnemosyne.Event_UPDATED, &mnemosyne.Session{SubjectId: "subject-1"})
+
+	assert.Len(t, all.events, 3)
+	_, ok := <-subject.events
+	assert.False(t, ok)
+}
+
+func TestEventBus_publish_slowWatcher(t *testing.T) {
+	eb := newEventBus(1)
+	slow := eb.watch("")
+
+	eb.publish(mnemosyne.Event_ABANDONED, &mnemosyne.Session{}, &mnemosyne.Session{})
+
+	assert.Len(t, eb.watchers, 0)
+	assert.Len(t, slow.events, 1)
+
+	<-slow.events
+	_, ok := <-slow.events
+	assert.False(t, ok)
+	assert.Equal(t, errWatcherTooSlow, slow.err)
+}
+
+func TestEventBus_publish_nil(t *testing.T) {
+	var eb *eventBus
+
+	assert.NotPanics(t, func() {
+		eb.publish(mnemosyne.Event_EXPIRED, &mnemosyne.Session{})
+	})
+}
+
+func TestEventBus_close(t *testing.T) {
+	eb := newEventBus(10)
+	before := eb.watch("")
+
+	eb.close()
+	after := eb.watch("")
+
+	assert.Len(t, eb.watchers, 0)
+	for _, ew := range []*eventWatcher{before, after} {
+		_, ok := <-ew.events
+		assert.False(t, ok)
+		assert.Equal(t, errEventBusClosed, ew.err)
+	}
+	assert.NotPanics(t, func() {
+		eb.unwatch(before)
+		eb.publish(mnemosyne.Event_CREATED, &mnemosyne.Session{})
+	})
+}
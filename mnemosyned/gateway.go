@@ -0,0 +1,446 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/piotrkowalczuk/protot"
+	"github.com/piotrkowalczuk/sklog"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// gatewayTokenHeader carries session token of the caller, it is required by context endpoint.
+	gatewayTokenHeader = "X-Mnemosyne-Token"
+	// gatewayAPIKeyHeader carries API key of the caller, it is required if authorization is enabled.
+	gatewayAPIKeyHeader = "X-Mnemosyne-Api-Key"
+)
+
+// gateway exposes RPC service as HTTP/JSON API. Requests are handled in-process by given server:
+//
+//	POST   /sessions                             Start
+//	GET    /sessions                             List
+//	DELETE /sessions                             Delete (by expiration time range)
+//	GET    /sessions/{token}                     Get
+//	HEAD   /sessions/{token}                     Exists
+//	DELETE /sessions/{token}                     Abandon
+//	POST   /sessions/{token}/touch               Touch
+//	DELETE /sessions/{token}/bag                 Clear
+//	PUT    /sessions/{token}/bag/{key}           SetValue
+//	DELETE /sessions/{token}/bag/{key}           DeleteValue
+//	DELETE /subjects/{subject_id}/sessions       AbandonAll
+//	GET    /session                              Context (token taken from X-Mnemosyne-Token header)
+//
+// Callers are identified by X-Mnemosyne-Api-Key header or by client certificate, the same way as RPC clients.
+type gateway struct {
+	logger log.Logger
+	server mnemosyne.RPCServer
+	mux    *http.ServeMux
+}
+
+// gatewaySession is JSON representation of mnemosyne.Session.
+type gatewaySession struct {
+	Token     string            `json:"token"`
+	SubjectID string            `json:"subjectId"`
+	Bag       map[string]string `json:"bag"`
+	ExpireAt  time.Time         `json:"expireAt"`
+}
+
+type gatewayError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func newGateway(server mnemosyne.RPCServer, logger log.Logger) *gateway {
+	gw := &gateway{
+		logger: logger,
+		server: server,
+		mux:    http.NewServeMux(),
+	}
+	gw.mux.HandleFunc("/sessions", gw.sessions)
+	gw.mux.HandleFunc("/sessions/", gw.session)
+	gw.mux.HandleFunc("/subjects/", gw.subject)
+	gw.mux.HandleFunc("/session", gw.context)
+
+	return gw
+}
+
+// ServeHTTP implements http.Handler interface.
+func (gw *gateway) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	gw.mux.ServeHTTP(rw, r)
+}
+
+func (gw *gateway) sessions(rw http.ResponseWriter, r *http.Request) {
+	ctx := gatewayContext(r)
+
+	switch r.Method {
+	case http.MethodPost:
+		var body struct {
+			SubjectID string            `json:"subjectId"`
+			Bag       map[string]string `json:"bag"`
+			// TTL is session lifetime in seconds.
+			TTL int64 `json:"ttl"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			gw.error(rw, grpc.Errorf(codes.InvalidArgument, "mnemosyned: malformed request body: %s", err.Error()))
+			return
+		}
+
+		res, err := gw.server.Start(ctx, &mnemosyne.StartRequest{
+			SubjectId: body.SubjectID,
+			Bag:       body.Bag,
+			Ttl:       body.TTL,
+		})
+		if err != nil {
+			gw.error(rw, err)
+			return
+		}
+
+		gw.respond(rw, http.StatusCreated, map[string]interface{}{"session": newGatewaySession(res.Session)})
+	case http.MethodGet:
+		query := r.URL.Query()
+		req := &mnemosyne.ListRequest{
+			SubjectId: query.Get("subjectId"),
+			BagKey:    query.Get("bagKey"),
+			PageToken: query.Get("pageToken"),
+		}
+
+		var err error
+		if req.Limit, err = gatewayInt(query.Get("limit"), 10); err != nil {
+			gw.error(rw, err)
+			return
+		}
+		if req.ExpireAtFrom, err = gatewayTimestamp(query.Get("expireAtFrom")); err != nil {
+			gw.error(rw, err)
+			return
+		}
+		if req.ExpireAtTo, err = gatewayTimestamp(query.Get("expireAtTo")); err != nil {
+			gw.error(rw, err)
+			return
+		}
+
+		res, err := gw.server.List(ctx, req)
+		if err != nil {
+			gw.error(rw, err)
+			return
+		}
+
+		sessions := make([]*gatewaySession, 0, len(res.Sessions))
+		for _, ses := range res.Sessions {
+			sessions = append(sessions, newGatewaySession(ses))
+		}
+
+		gw.respond(rw, http.StatusOK, map[string]interface{}{
+			"sessions":      sessions,
+			"nextPageToken": res.NextPageToken,
+		})
+	case http.MethodDelete:
+		query := r.URL.Query()
+		req := &mnemosyne.DeleteRequest{}
+
+		var err error
+		if req.ExpireAtFrom, err = gatewayTimestamp(query.Get("expireAtFrom")); err != nil {
+			gw.error(rw, err)
+			return
+		}
+		if req.ExpireAtTo, err = gatewayTimestamp(query.Get("expireAtTo")); err != nil {
+			gw.error(rw, err)
+			return
+		}
+
+		res, err := gw.server.Delete(ctx, req)
+		if err != nil {
+			gw.error(rw, err)
+			return
+		}
+
+		gw.respond(rw, http.StatusOK, map[string]interface{}{"count": res.Count})
+	default:
+		gw.notAllowed(rw, http.MethodPost, http.MethodGet, http.MethodDelete)
+	}
+}
+
+func (gw *gateway) session(rw http.ResponseWriter, r *http.Request) {
+	ctx := gatewayContext(r)
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/sessions/"), "/", 3)
+	token := mnemosyne.DecodeTokenString(parts[0])
+
+	switch {
+	case len(parts) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			res, err := gw.server.Get(ctx, &mnemosyne.GetRequest{Token: &token})
+			if err != nil {
+				gw.error(rw, err)
+				return
+			}
+
+			gw.respond(rw, http.StatusOK, map[string]interface{}{"session": newGatewaySession(res.Session)})
+		case http.MethodHead:
+			res, err := gw.server.Exists(ctx, &mnemosyne.ExistsRequest{Token: &token})
+			switch {
+			case err != nil:
+				gw.error(rw, err)
+			case res.Exists:
+				rw.WriteHeader(http.StatusOK)
+			default:
+				rw.WriteHeader(http.StatusNotFound)
+			}
+		case http.MethodDelete:
+			res, err := gw.server.Abandon(ctx, &mnemosyne.AbandonRequest{Token: &token})
+			if err != nil {
+				gw.error(rw, err)
+				return
+			}
+
+			gw.respond(rw, http.StatusOK, map[string]interface{}{"abandoned": res.Abandoned})
+		default:
+			gw.notAllowed(rw, http.MethodGet, http.MethodHead, http.MethodDelete)
+		}
+	case parts[1] == "touch" && len(parts) == 2:
+		if r.Method != http.MethodPost {
+			gw.notAllowed(rw, http.MethodPost)
+			return
+		}
+
+		// Body is optional, without it session lifetime is extended by server default.
+		var body struct {
+			// TTL is session lifetime in seconds counted from now.
+			TTL int64 `json:"ttl"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+			gw.error(rw, grpc.Errorf(codes.InvalidArgument, "mnemosyned: malformed request body: %s", err.Error()))
+			return
+		}
+
+		res, err := gw.server.Touch(ctx, &mnemosyne.TouchRequest{Token: &token, Ttl: body.TTL})
+		if err != nil {
+			gw.error(rw, err)
+			return
+		}
+
+		gw.respond(rw, http.StatusOK, map[string]interface{}{"session": newGatewaySession(res.Session)})
+	case parts[1] == "bag" && len(parts) == 2:
+		if r.Method != http.MethodDelete {
+			gw.notAllowed(rw, http.MethodDelete)
+			return
+		}
+
+		if _, err := gw.server.Clear(ctx, &mnemosyne.ClearRequest{Token: &token}); err != nil {
+			gw.error(rw, err)
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	case parts[1] == "bag":
+		switch r.Method {
+		case http.MethodPut:
+			var body struct {
+				Value string `json:"value"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				gw.error(rw, grpc.Errorf(codes.InvalidArgument, "mnemosyned: malformed request body: %s", err.Error()))
+				return
+			}
+
+			res, err := gw.server.SetValue(ctx, &mnemosyne.SetValueRequest{Token: &token, Key: parts[2], Value: body.Value})
+			if err != nil {
+				gw.error(rw, err)
+				return
+			}
+
+			gw.respond(rw, http.StatusOK, map[string]interface{}{"bag": res.Bag})
+		case http.MethodDelete:
+			res, err := gw.server.DeleteValue(ctx, &mnemosyne.DeleteValueRequest{Token: &token, Key: parts[2]})
+			if err != nil {
+				gw.error(rw, err)
+				return
+			}
+
+			gw.respond(rw, http.StatusOK, map[string]interface{}{"session": newGatewaySession(res.Session)})
+		default:
+			gw.notAllowed(rw, http.MethodPut, http.MethodDelete)
+		}
+	default:
+		http.NotFound(rw, r)
+	}
+}
+
+func (gw *gateway) subject(rw http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/subjects/"), "/")
+	if len(parts) != 2 || parts[1] != "sessions" {
+		http.NotFound(rw, r)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		gw.notAllowed(rw, http.MethodDelete)
+		return
+	}
+
+	req := &mnemosyne.AbandonAllRequest{SubjectId: parts[0]}
+	if except := r.URL.Query().Get("except"); except != "" {
+		token := mnemosyne.DecodeTokenString(except)
+		req.Except = &token
+	}
+
+	res, err := gw.server.AbandonAll(gatewayContext(r), req)
+	if err != nil {
+		gw.error(rw, err)
+		return
+	}
+
+	gw.respond(rw, http.StatusOK, map[string]interface{}{"count": res.Count})
+}
+
+func (gw *gateway) context(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		gw.notAllowed(rw, http.MethodGet)
+		return
+	}
+
+	token := r.Header.Get(gatewayTokenHeader)
+	if token == "" {
+		gw.error(rw, mnemosyne.ErrMissingToken)
+		return
+	}
+
+	ses, err := gw.server.Context(gatewayContext(r, mnemosyne.TokenMetadataKey, token), &mnemosyne.Empty{})
+	if err != nil {
+		gw.error(rw, err)
+		return
+	}
+
+	gw.respond(rw, http.StatusOK, map[string]interface{}{"session": newGatewaySession(ses)})
+}
+
+func (gw *gateway) respond(rw http.ResponseWriter, status int, body interface{}) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	rw.WriteHeader(status)
+
+	if err := json.NewEncoder(rw).Encode(body); err != nil {
+		sklog.Error(gw.logger, err)
+	}
+}
+
+func (gw *gateway) error(rw http.ResponseWriter, err error) {
+	code := grpc.Code(err)
+
+	gw.respond(rw, gatewayStatus(code), &gatewayError{
+		Code:    code.String(),
+		Message: grpc.ErrorDesc(err),
+	})
+}
+
+func (gw *gateway) notAllowed(rw http.ResponseWriter, methods ...string) {
+	rw.Header().Set("Allow", strings.Join(methods, ", "))
+	gw.respond(rw, http.StatusMethodNotAllowed, &gatewayError{
+		Code:    codes.Unimplemented.String(),
+		Message: "mnemosyned: method not allowed",
+	})
+}
+
+// gatewayStatus maps gRPC status code to HTTP status code.
+func gatewayStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return http.StatusRequestTimeout
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// gatewayContext returns request context with given metadata and caller credentials attached,
+// so they can be retrieved by RPC server as if the request came over gRPC.
+func gatewayContext(r *http.Request, pairs ...string) context.Context {
+	ctx := r.Context()
+	if key := r.Header.Get(gatewayAPIKeyHeader); key != "" {
+		pairs = append(pairs, mnemosyne.APIKeyMetadataKey, key)
+	}
+	if len(pairs) > 0 {
+		ctx = metadata.NewContext(ctx, metadata.Pairs(pairs...))
+	}
+	if r.TLS != nil {
+		ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: *r.TLS}})
+	}
+
+	return ctx
+}
+
+func newGatewaySession(ses *mnemosyne.Session) *gatewaySession {
+	if ses == nil {
+		return nil
+	}
+
+	gs := &gatewaySession{
+		SubjectID: ses.SubjectId,
+		Bag:       ses.Bag,
+	}
+	if ses.Token != nil {
+		gs.Token = ses.Token.Encode()
+	}
+	if ses.ExpireAt != nil {
+		gs.ExpireAt = ses.ExpireAt.Time().UTC()
+	}
+
+	return gs
+}
+
+func gatewayInt(s string, def int64) (int64, error) {
+	if s == "" {
+		return def, nil
+	}
+
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, grpc.Errorf(codes.InvalidArgument, "mnemosyned: malformed integer: %s", s)
+	}
+
+	return i, nil
+}
+
+// gatewayTimestamp parses time in RFC 3339 format, empty string results in nil timestamp.
+func gatewayTimestamp(s string) (*protot.Timestamp, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "mnemosyned: malformed time, expected RFC 3339 format: %s", s)
+	}
+
+	return protot.TimeToTimestamp(t), nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/piotrkowalczuk/mnemosyne/mnemosynetest"
+	"github.com/piotrkowalczuk/protot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestGateway(t *testing.T) {
+	token := mnemosyne.NewToken([]byte("key"), []byte("hash"))
+	expireAt := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	session := &mnemosyne.Session{
+		Token:     &token,
+		SubjectId: "subject",
+		Bag:       map[string]string{"key": "value"},
+		ExpireAt:  protot.TimeToTimestamp(expireAt),
+	}
+
+	data := map[string]struct {
+		method, path, body string
+		header             http.Header
+		init               func(*mnemosynetest.RPCServer)
+		status             int
+		response           string
+	}{
+		"start": {
+			method: http.MethodPost,
+			path:   "/sessions",
+			body:   `{"subjectId": "subject", "bag": {"key": "value"}, "ttl": 60}`,
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("Start", mock.Anything, &mnemosyne.StartRequest{SubjectId: "subject", Bag: session.Bag, Ttl: 60}).
+					Return(&mnemosyne.StartResponse{Session: session}, nil).Once()
+			},
+			status:   http.StatusCreated,
+			response: `{"session":{"token":"` + token.Encode() + `","subjectId":"subject","bag":{"key":"value"},"expireAt":"2020-01-01T12:00:00Z"}}`,
+		},
+		"start-malformed-body": {
+			method:   http.MethodPost,
+			path:     "/sessions",
+			body:     `{`,
+			status:   http.StatusBadRequest,
+			response: `{"code":"InvalidArgument","message":"mnemosyned: malformed request body: unexpected EOF"}`,
+		},
+		"start-missing-subject": {
+			method: http.MethodPost,
+			path:   "/sessions",
+			body:   `{}`,
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("Start", mock.Anything, &mnemosyne.StartRequest{}).
+					Return(nil, mnemosyne.ErrMissingSubjectID).Once()
+			},
+			status:   http.StatusBadRequest,
+			response: `{"code":"InvalidArgument","message":"mnemosyne: missing subject id"}`,
+		},
+		"list": {
+			method: http.MethodGet,
+			path:   "/sessions?subjectId=subject&limit=5&expireAtFrom=2020-01-01T00:00:00Z",
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("List", mock.Anything, mock.MatchedBy(func(req *mnemosyne.ListRequest) bool {
+					return req.SubjectId == "subject" && req.Limit == 5 && req.ExpireAtTo == nil &&
+						req.ExpireAtFrom.Time().Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+				})).Return(&mnemosyne.ListResponse{Sessions: []*mnemosyne.Session{session}, NextPageToken: "next"}, nil).Once()
+			},
+			status:   http.StatusOK,
+			response: `{"nextPageToken":"next","sessions":[{"token":"` + token.Encode() + `","subjectId":"subject","bag":{"key":"value"},"expireAt":"2020-01-01T12:00:00Z"}]}`,
+		},
+		"list-malformed-time": {
+			method:   http.MethodGet,
+			path:     "/sessions?expireAtTo=yesterday",
+			status:   http.StatusBadRequest,
+			response: `{"code":"InvalidArgument","message":"mnemosyned: malformed time, expected RFC 3339 format: yesterday"}`,
+		},
+		"get": {
+			method: http.MethodGet,
+			path:   "/sessions/" + token.Encode(),
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("Get", mock.Anything, &mnemosyne.GetRequest{Token: &token}).
+					Return(&mnemosyne.GetResponse{Session: session}, nil).Once()
+			},
+			status:   http.StatusOK,
+			response: `{"session":{"token":"` + token.Encode() + `","subjectId":"subject","bag":{"key":"value"},"expireAt":"2020-01-01T12:00:00Z"}}`,
+		},
+		"get-api-key": {
+			method: http.MethodGet,
+			path:   "/sessions/" + token.Encode(),
+			header: http.Header{gatewayAPIKeyHeader: []string{"secret"}},
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("Get", mock.MatchedBy(func(ctx context.Context) bool {
+					md, ok := metadata.FromContext(ctx)
+					return ok && len(md[mnemosyne.APIKeyMetadataKey]) == 1 && md[mnemosyne.APIKeyMetadataKey][0] == "secret"
+				}), &mnemosyne.GetRequest{Token: &token}).Return(nil, grpc.Errorf(codes.PermissionDenied, "denied")).Once()
+			},
+			status:   http.StatusForbidden,
+			response: `{"code":"PermissionDenied","message":"denied"}`,
+		},
+		"get-not-found": {
+			method: http.MethodGet,
+			path:   "/sessions/" + token.Encode(),
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("Get", mock.Anything, &mnemosyne.GetRequest{Token: &token}).
+					Return(nil, mnemosyne.ErrSessionNotFound).Once()
+			},
+			status:   http.StatusNotFound,
+			response: `{"code":"NotFound","message":"mnemosyne: session not found"}`,
+		},
+		"exists": {
+			method: http.MethodHead,
+			path:   "/sessions/" + token.Encode(),
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("Exists", mock.Anything, &mnemosyne.ExistsRequest{Token: &token}).
+					Return(&mnemosyne.ExistsResponse{Exists: false}, nil).Once()
+			},
+			status: http.StatusNotFound,
+		},
+		"abandon": {
+			method: http.MethodDelete,
+			path:   "/sessions/" + token.Encode(),
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("Abandon", mock.Anything, &mnemosyne.AbandonRequest{Token: &token}).
+					Return(&mnemosyne.AbandonResponse{Abandoned: true}, nil).Once()
+			},
+			status:   http.StatusOK,
+			response: `{"abandoned":true}`,
+		},
+		"set-value": {
+			method: http.MethodPut,
+			path:   "/sessions/" + token.Encode() + "/bag/key",
+			body:   `{"value": "value"}`,
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("SetValue", mock.Anything, &mnemosyne.SetValueRequest{Token: &token, Key: "key", Value: "value"}).
+					Return(&mnemosyne.SetValueResponse{Bag: session.Bag}, nil).Once()
+			},
+			status:   http.StatusOK,
+			response: `{"bag":{"key":"value"}}`,
+		},
+		"clear": {
+			method: http.MethodDelete,
+			path:   "/sessions/" + token.Encode() + "/bag",
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("Clear", mock.Anything, &mnemosyne.ClearRequest{Token: &token}).
+					Return(&mnemosyne.ClearResponse{}, nil).Once()
+			},
+			status: http.StatusNoContent,
+		},
+		"delete": {
+			method: http.MethodDelete,
+			path:   "/sessions?expireAtTo=2020-01-01T00:00:00Z",
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("Delete", mock.Anything, mock.AnythingOfType("*mnemosyne.DeleteRequest")).
+					Return(&mnemosyne.DeleteResponse{Count: 3}, nil).Once()
+			},
+			status:   http.StatusOK,
+			response: `{"count":3}`,
+		},
+		"abandon-all": {
+			method: http.MethodDelete,
+			path:   "/subjects/subject/sessions?except=" + token.Encode(),
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("AbandonAll", mock.Anything, mock.MatchedBy(func(req *mnemosyne.AbandonAllRequest) bool {
+					return req.SubjectId == "subject" && req.Except != nil && req.Except.Encode() == token.Encode()
+				})).Return(&mnemosyne.AbandonAllResponse{Count: 2}, nil).Once()
+			},
+			status:   http.StatusOK,
+			response: `{"count":2}`,
+		},
+		"context": {
+			method: http.MethodGet,
+			path:   "/session",
+			header: http.Header{gatewayTokenHeader: []string{token.Encode()}},
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("Context", mock.MatchedBy(func(ctx context.Context) bool {
+					md, ok := metadata.FromContext(ctx)
+					return ok && len(md[mnemosyne.TokenMetadataKey]) == 1 && md[mnemosyne.TokenMetadataKey][0] == token.Encode()
+				}), &mnemosyne.Empty{}).Return(session, nil).Once()
+			},
+			status:   http.StatusOK,
+			response: `{"session":{"token":"` + token.Encode() + `","subjectId":"subject","bag":{"key":"value"},"expireAt":"2020-01-01T12:00:00Z"}}`,
+		},
+		"context-missing-token": {
+			method:   http.MethodGet,
+			path:     "/session",
+			status:   http.StatusBadRequest,
+			response: `{"code":"InvalidArgument","message":"mnemosyne: missing token"}`,
+		},
+		"touch": {
+			method: http.MethodPost,
+			path:   "/sessions/" + token.Encode() + "/touch",
+			body:   `{"ttl": 7200}`,
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("Touch", mock.Anything, &mnemosyne.TouchRequest{Token: &token, Ttl: 7200}).
+					Return(&mnemosyne.TouchResponse{Session: session}, nil).Once()
+			},
+			status:   http.StatusOK,
+			response: `{"session":{"token":"` + token.Encode() + `","subjectId":"subject","bag":{"key":"value"},"expireAt":"2020-01-01T12:00:00Z"}}`,
+		},
+		"internal-error": {
+			method: http.MethodPost,
+			path:   "/sessions/" + token.Encode() + "/touch",
+			init: func(srv *mnemosynetest.RPCServer) {
+				srv.On("Touch", mock.Anything, &mnemosyne.TouchRequest{Token: &token}).
+					Return(nil, grpc.Errorf(codes.Internal, "storage failure")).Once()
+			},
+			status:   http.StatusInternalServerError,
+			response: `{"code":"Internal","message":"storage failure"}`,
+		},
+		"method-not-allowed": {
+			method: http.MethodPatch,
+			path:   "/sessions/" + token.Encode(),
+			status: http.StatusMethodNotAllowed,
+		},
+		"unknown-path": {
+			method: http.MethodGet,
+			path:   "/sessions/" + token.Encode() + "/unknown",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for hint, d := range data {
+		srv := &mnemosynetest.RPCServer{}
+		if d.init != nil {
+			d.init(srv)
+		}
+
+		r := httptest.NewRequest(d.method, d.path, strings.NewReader(d.body))
+		for key, values := range d.header {
+			r.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+
+		newGateway(srv, log.NewNopLogger()).ServeHTTP(rec, r)
+
+		assert.Equal(t, d.status, rec.Code, hint)
+		if d.response != "" {
+			var expected, got interface{}
+			require.NoError(t, json.Unmarshal([]byte(d.response), &expected), hint)
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got), hint)
+			assert.Equal(t, expected, got, hint)
+		}
+		srv.AssertExpectations(t)
+	}
+}
@@ -7,34 +7,62 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics"
 	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/piotrkowalczuk/sklog"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 )
 
-type handlerFunc func(logger log.Logger, storage Storage, monitor monitoringRPC) *handler
+type handlerFunc func(logger log.Logger, storage Storage, monitor monitoringRPC, opts handlerOpts) *handler
 
 type handler struct {
-	logger  log.Logger
-	storage Storage
-	monitor monitoringRPC
+	endpoint string
+	logger   log.Logger
+	storage  Storage
+	monitor  monitoringRPC
+	opts     handlerOpts
+}
+
+// handlerOpts holds server wide settings shared by every handler.
+type handlerOpts struct {
+	// ttl is default session lifetime.
+	ttl time.Duration
+	// ttlMax is an upper limit of session lifetime that can be requested by the client.
+	ttlMax time.Duration
+	// touch if true, session lifetime is extended on every get and context call.
+	touch bool
+	// events receives lifecycle events of sessions modified by the handler, can be nil.
+	events *eventBus
+	// watch if true, clients can watch session events.
+	watch bool
+	// propagated if true, events are published by the storage itself (e.g. postgres notifications) instead of handlers.
+	propagated bool
+	// authorizer restricts endpoints available to the callers, every caller can call every endpoint if it is nil.
+	authorizer *authorizer
 }
 
 func newHandlerFunc(endpoint string) handlerFunc {
-	return func(logger log.Logger, storage Storage, monitor monitoringRPC) *handler {
+	return func(logger log.Logger, storage Storage, monitor monitoringRPC, opts handlerOpts) *handler {
 		return &handler{
-			logger:  log.NewContext(logger).With("endpoint", endpoint),
-			storage: storage,
+			endpoint: endpoint,
+			logger:   log.NewContext(logger).With("endpoint", endpoint),
+			storage:  storage,
 			monitor: monitoringRPC{
-				errors:   monitor.errors.With(metrics.Field{Key: "endpoint", Value: endpoint}),
-				requests: monitor.requests.With(metrics.Field{Key: "endpoint", Value: endpoint}),
+				errors:   monitor.errors.With(metrics.Field{Key: "method", Value: endpoint}),
+				requests: monitor.requests.With(metrics.Field{Key: "method", Value: endpoint}),
+				duration: monitor.duration.With(metrics.Field{Key: "method", Value: endpoint}),
 			},
+			opts: opts,
 		}
 	}
 }
 
 func (h *handler) context(ctx context.Context) (*mnemosyne.Session, error) {
+	if err := h.authorize(ctx); err != nil {
+		return nil, err
+	}
+
 	md, ok := metadata.FromContext(ctx)
 	if !ok {
 		return nil, errors.New("mnemosyned: missing metadata in context, session token cannot be retrieved")
@@ -48,51 +76,105 @@ func (h *handler) context(ctx context.Context) (*mnemosyne.Session, error) {
 
 	h.logger = log.NewContext(h.logger).With("token", token.String())
 
+	if h.opts.touch {
+		return h.updated(h.storage.Touch(&token, h.opts.ttl))
+	}
 	return h.storage.Get(&token)
 }
 
 func (h *handler) get(ctx context.Context, req *mnemosyne.GetRequest) (*mnemosyne.Session, error) {
+	if err := h.authorize(ctx); err != nil {
+		return nil, err
+	}
+
 	if req.Token == nil {
 		return nil, mnemosyne.ErrMissingToken
 	}
 
 	h.logger = log.NewContext(h.logger).With("token", req.Token.String())
 
+	if h.opts.touch {
+		return h.updated(h.storage.Touch(req.Token, h.opts.ttl))
+	}
 	return h.storage.Get(req.Token)
 }
 
-func (h *handler) list(ctx context.Context, req *mnemosyne.ListRequest) ([]*mnemosyne.Session, error) {
-	expireAtFrom := req.ExpireAtFrom.Time()
-	expireAtTo := req.ExpireAtTo.Time()
+func (h *handler) list(ctx context.Context, req *mnemosyne.ListRequest) ([]*mnemosyne.Session, string, error) {
+	if err := h.authorize(ctx); err != nil {
+		return nil, "", err
+	}
+
+	switch {
+	case req.Offset != 0:
+		return nil, "", grpc.Errorf(codes.InvalidArgument, "mnemosyne: offset is not supported, page token should be used instead")
+	case req.Limit <= 0:
+		return nil, "", grpc.Errorf(codes.InvalidArgument, "mnemosyne: limit needs to be higher than 0")
+	}
+
+	var expireAtFrom, expireAtTo *time.Time
+	if req.ExpireAtFrom != nil {
+		eaf := req.ExpireAtFrom.Time()
+		expireAtFrom = &eaf
+	}
+	if req.ExpireAtTo != nil {
+		eat := req.ExpireAtTo.Time()
+		expireAtTo = &eat
+	}
 
 	h.logger = log.NewContext(h.logger).With(
-		"offset", req.Offset,
+		"page_token", req.PageToken,
 		"limit", req.Limit,
-		"expire_at_from", expireAtFrom.String(),
-		"expire_at_to", expireAtTo.String(),
+		"subject_id", req.SubjectId,
+		"bag_key", req.BagKey,
+		"expire_at_from", expireAtFrom,
+		"expire_at_to", expireAtTo,
 	)
 
-	return h.storage.List(req.Offset, req.Limit, &expireAtFrom, &expireAtTo)
+	sessions, err := h.storage.List(req.PageToken, req.Limit, req.SubjectId, req.BagKey, expireAtFrom, expireAtTo)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Full page suggests that there could be more sessions to retrieve.
+	if int64(len(sessions)) < req.Limit {
+		return sessions, "", nil
+	}
+
+	return sessions, newCursor(sessions[len(sessions)-1]).encode(), nil
 }
 
 func (h *handler) start(ctx context.Context, req *mnemosyne.StartRequest) (*mnemosyne.Session, error) {
+	if err := h.authorize(ctx); err != nil {
+		return nil, err
+	}
+
 	if req.SubjectId == "" {
 		return nil, mnemosyne.ErrMissingSubjectID
 	}
 
-	h.logger = log.NewContext(h.logger).With("subject_id", req.SubjectId)
+	ttl, err := h.lifetime(req.Ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger = log.NewContext(h.logger).With("subject_id", req.SubjectId, "ttl", ttl)
 
-	ses, err := h.storage.Start(req.SubjectId, req.Bag)
+	ses, err := h.storage.Start(req.SubjectId, req.Bag, ttl)
 	if err != nil {
 		return nil, err
 	}
 
 	h.logger = log.NewContext(h.logger).With("token", ses.Token, "expire_at", ses.ExpireAt.Time().Format(time.RFC3339))
+	h.publish(mnemosyne.Event_CREATED, ses)
 
 	return ses, nil
 }
 
 func (h *handler) exists(ctx context.Context, req *mnemosyne.ExistsRequest) (bool, error) {
+	if err := h.authorize(ctx); err != nil {
+		return false, err
+	}
+
 	if req.Token == nil {
 		return false, mnemosyne.ErrMissingToken
 	}
@@ -110,23 +192,80 @@ func (h *handler) exists(ctx context.Context, req *mnemosyne.ExistsRequest) (boo
 }
 
 func (h *handler) abandon(ctx context.Context, req *mnemosyne.AbandonRequest) (bool, error) {
+	if err := h.authorize(ctx); err != nil {
+		return false, err
+	}
+
 	if req.Token == nil {
 		return false, mnemosyne.ErrMissingToken
 	}
 
 	h.logger = log.NewContext(h.logger).With("token", req.Token)
 
-	abandoned, err := h.storage.Abandon(req.Token)
+	ses, err := h.storage.Abandon(req.Token)
 	if err != nil {
 		return false, err
 	}
 
-	h.logger = log.NewContext(h.logger).With("token", req.Token)
+	h.publish(mnemosyne.Event_ABANDONED, ses)
+
+	return true, nil
+}
+
+func (h *handler) abandonAll(ctx context.Context, req *mnemosyne.AbandonAllRequest) (int64, error) {
+	if err := h.authorize(ctx); err != nil {
+		return 0, err
+	}
+
+	if req.SubjectId == "" {
+		return 0, mnemosyne.ErrMissingSubjectID
+	}
 
-	return abandoned, nil
+	h.logger = log.NewContext(h.logger).With("subject_id", req.SubjectId, "except", req.Except)
+
+	abandoned, err := h.storage.AbandonAll(req.SubjectId, req.Except)
+	if err != nil {
+		return 0, err
+	}
+
+	h.logger = log.NewContext(h.logger).With("affected", len(abandoned))
+	h.publish(mnemosyne.Event_ABANDONED, abandoned...)
+
+	return int64(len(abandoned)), nil
+}
+
+func (h *handler) touch(ctx context.Context, req *mnemosyne.TouchRequest) (*mnemosyne.Session, error) {
+	if err := h.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	if req.Token == nil {
+		return nil, mnemosyne.ErrMissingToken
+	}
+
+	ttl, err := h.lifetime(req.Ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger = log.NewContext(h.logger).With("token", req.Token, "ttl", ttl)
+
+	ses, err := h.storage.Touch(req.Token, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger = log.NewContext(h.logger).With("expire_at", ses.ExpireAt.Time().Format(time.RFC3339))
+	h.publish(mnemosyne.Event_UPDATED, ses)
+
+	return ses, nil
 }
 
 func (h *handler) setValue(ctx context.Context, req *mnemosyne.SetValueRequest) (map[string]string, error) {
+	if err := h.authorize(ctx); err != nil {
+		return nil, err
+	}
+
 	switch {
 	case req.Token == nil:
 		return nil, mnemosyne.ErrMissingToken
@@ -136,26 +275,146 @@ func (h *handler) setValue(ctx context.Context, req *mnemosyne.SetValueRequest)
 
 	h.logger = log.NewContext(h.logger).With("token", req.Token, "key", req.Key, "value", req.Value)
 
-	bag, err := h.storage.SetValue(req.Token, req.Key, req.Value)
+	ses, err := h.storage.SetValue(req.Token, req.Key, req.Value)
 	if err != nil {
 		return nil, err
 	}
 
-	return bag, nil
+	h.publish(mnemosyne.Event_UPDATED, ses)
+
+	return ses.Bag, nil
+}
+
+func (h *handler) deleteValue(ctx context.Context, req *mnemosyne.DeleteValueRequest) (*mnemosyne.Session, error) {
+	if err := h.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.Token == nil:
+		return nil, mnemosyne.ErrMissingToken
+	case req.Key == "":
+		return nil, grpc.Errorf(codes.InvalidArgument, "mnemosyne: missing bag key")
+	}
+
+	h.logger = log.NewContext(h.logger).With("token", req.Token, "key", req.Key)
+
+	return h.updated(h.storage.DeleteValue(req.Token, req.Key))
+}
+
+func (h *handler) clear(ctx context.Context, req *mnemosyne.ClearRequest) (*mnemosyne.Session, error) {
+	if err := h.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	if req.Token == nil {
+		return nil, mnemosyne.ErrMissingToken
+	}
+
+	h.logger = log.NewContext(h.logger).With("token", req.Token)
+
+	return h.updated(h.storage.Clear(req.Token))
 }
 
 func (h *handler) delete(ctx context.Context, req *mnemosyne.DeleteRequest) (int64, error) {
-	expireAtFrom := req.ExpireAtFrom.Time()
-	expireAtTo := req.ExpireAtTo.Time()
+	if err := h.authorize(ctx); err != nil {
+		return 0, err
+	}
+
+	var expireAtFrom, expireAtTo *time.Time
+	if req.ExpireAtFrom != nil {
+		eaf := req.ExpireAtFrom.Time()
+		expireAtFrom = &eaf
+	}
+	if req.ExpireAtTo != nil {
+		eat := req.ExpireAtTo.Time()
+		expireAtTo = &eat
+	}
 
 	h.logger = log.NewContext(h.logger).With("token", req.Token, "expire_at_from", expireAtFrom, "expire_at_to", expireAtTo)
 
-	affected, err := h.storage.Delete(req.Token, &expireAtFrom, &expireAtTo)
+	deleted, err := h.storage.Delete(req.Token, expireAtFrom, expireAtTo)
 	if err != nil {
 		return 0, err
 	}
 
-	h.logger = log.NewContext(h.logger).With("affected", affected)
+	h.logger = log.NewContext(h.logger).With("affected", len(deleted))
+	h.publish(mnemosyne.Event_ABANDONED, deleted...)
+
+	return int64(len(deleted)), nil
+}
 
-	return affected, nil
+func (h *handler) watch(ctx context.Context, req *mnemosyne.WatchRequest) (*eventWatcher, error) {
+	if err := h.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	if h.opts.events == nil || !h.opts.watch {
+		return nil, grpc.Errorf(codes.Unimplemented, "mnemosyne: session events are not enabled")
+	}
+
+	h.logger = log.NewContext(h.logger).With("subject_id", req.SubjectId)
+
+	return h.opts.events.watch(req.SubjectId), nil
+}
+
+// lifetime returns session lifetime requested in seconds, server default is used if not provided.
+func (h *handler) lifetime(seconds int64) (time.Duration, error) {
+	switch {
+	case seconds < 0:
+		return 0, grpc.Errorf(codes.InvalidArgument, "mnemosyne: session ttl cannot be negative")
+	case seconds == 0:
+		return h.opts.ttl, nil
+	}
+
+	ttl := time.Duration(seconds) * time.Second
+	if ttl > h.opts.ttlMax {
+		return 0, grpc.Errorf(codes.InvalidArgument, "mnemosyne: session ttl cannot be longer than %s", h.opts.ttlMax)
+	}
+
+	return ttl, nil
+}
+
+// failed records and logs error returned to the client, it is expected to carry gRPC status code.
+func (h *handler) failed(err error) {
+	h.monitor.errors.With(metrics.Field{Key: "code", Value: grpc.Code(err).String()}).Add(1)
+	sklog.Error(h.logger, err)
+}
+
+// measure observes duration of the call started at given time.
+func (h *handler) measure(start time.Time) {
+	h.monitor.duration.Observe(int64(time.Since(start) / time.Microsecond))
+}
+
+// authorize returns an error if the caller is not allowed to call handled endpoint.
+func (h *handler) authorize(ctx context.Context) error {
+	if h.opts.authorizer == nil {
+		return nil
+	}
+
+	caller, err := h.opts.authorizer.authorize(ctx, h.endpoint)
+	if caller != nil {
+		h.logger = log.NewContext(h.logger).With("caller", caller.Name)
+	}
+
+	return err
+}
+
+func (h *handler) publish(typ mnemosyne.Event_Type, sessions ...*mnemosyne.Session) {
+	if h.opts.propagated {
+		return
+	}
+
+	h.opts.events.publish(typ, sessions...)
+}
+
+// updated publishes update event if session was modified successfully.
+func (h *handler) updated(ses *mnemosyne.Session, err error) (*mnemosyne.Session, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	h.publish(mnemosyne.Event_UPDATED, ses)
+
+	return ses, nil
 }
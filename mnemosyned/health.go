@@ -0,0 +1,142 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/piotrkowalczuk/sklog"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// healthServiceName is the name under which mnemosyne service status can be checked.
+	// Empty name refers to the server as a whole, both report the same status.
+	healthServiceName = "mnemosyne.RPC"
+)
+
+// healthChecker periodically pings the storage to find out if daemon is ready to serve requests.
+// Status is exposed using standard gRPC health checking protocol and over HTTP as liveness and readiness probes.
+// Once shutdown begins, daemon is reported as not serving regardless of storage state.
+type healthChecker struct {
+	sync.RWMutex
+	logger   log.Logger
+	storage  Storage
+	interval time.Duration
+	status   healthpb.HealthCheckResponse_ServingStatus
+	stopping bool
+	done     chan struct{}
+}
+
+func newHealthChecker(logger log.Logger, storage Storage, interval time.Duration) *healthChecker {
+	return &healthChecker{
+		logger:   log.NewContext(logger).With("interval", interval),
+		storage:  storage,
+		interval: interval,
+		status:   healthpb.HealthCheckResponse_UNKNOWN,
+		done:     make(chan struct{}),
+	}
+}
+
+// start pings the storage right away and then runs health check loop in separate goroutine.
+func (hc *healthChecker) start() {
+	hc.check()
+	go hc.run()
+
+	sklog.Info(hc.logger, "health checker has been started")
+}
+
+// stop terminates health check loop.
+func (hc *healthChecker) stop() {
+	close(hc.done)
+}
+
+// shutdown marks daemon as not serving, so clients and load balancers can stop sending new calls.
+func (hc *healthChecker) shutdown() {
+	hc.Lock()
+	defer hc.Unlock()
+
+	hc.stopping = true
+	hc.status = healthpb.HealthCheckResponse_NOT_SERVING
+}
+
+func (hc *healthChecker) run() {
+	ticker := time.NewTicker(hc.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			hc.check()
+		case <-hc.done:
+			return
+		}
+	}
+}
+
+// check pings the storage and updates the status, changes are logged.
+func (hc *healthChecker) check() {
+	err := hc.storage.Ping()
+
+	hc.Lock()
+	defer hc.Unlock()
+
+	if hc.stopping {
+		return
+	}
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if err != nil {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	if status == hc.status {
+		return
+	}
+	hc.status = status
+
+	if err != nil {
+		sklog.Error(hc.logger, err)
+		return
+	}
+	sklog.Info(hc.logger, "storage is available, daemon is serving")
+}
+
+func (hc *healthChecker) serving() healthpb.HealthCheckResponse_ServingStatus {
+	hc.RLock()
+	defer hc.RUnlock()
+
+	return hc.status
+}
+
+// Check implements grpc_health_v1.HealthServer interface.
+func (hc *healthChecker) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.Service != "" && req.Service != healthServiceName {
+		return nil, grpc.Errorf(codes.NotFound, "mnemosyned: unknown service: %s", req.Service)
+	}
+
+	return &healthpb.HealthCheckResponse{Status: hc.serving()}, nil
+}
+
+// live responds successfully as long as the process is able to handle requests at all.
+func (hc *healthChecker) live(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	rw.WriteHeader(http.StatusOK)
+	rw.Write([]byte("OK\n"))
+}
+
+// ready responds successfully only if storage is available and shutdown did not begin.
+func (hc *healthChecker) ready(rw http.ResponseWriter, r *http.Request) {
+	status := hc.serving()
+
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if status == healthpb.HealthCheckResponse_SERVING {
+		rw.WriteHeader(http.StatusOK)
+	} else {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+	rw.Write([]byte(status.String() + "\n"))
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealthChecker(t *testing.T) {
+	storage := &storageMock{}
+	storage.On("Ping").Return(errors.New("fake storage error")).Once()
+	storage.On("Ping").Return(nil).Twice()
+
+	hc := newHealthChecker(log.NewNopLogger(), storage, time.Minute)
+
+	assertStatus := func(expected healthpb.HealthCheckResponse_ServingStatus, ready int, hint string) {
+		for _, service := range []string{"", healthServiceName} {
+			res, err := hc.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+			require.NoError(t, err, hint)
+			assert.Equal(t, expected, res.Status, "%s: service %q", hint, service)
+		}
+
+		rec := httptest.NewRecorder()
+		hc.ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Equal(t, ready, rec.Code, hint)
+
+		rec = httptest.NewRecorder()
+		hc.live(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Equal(t, http.StatusOK, rec.Code, "%s: liveness should not depend on storage", hint)
+	}
+
+	assertStatus(healthpb.HealthCheckResponse_UNKNOWN, http.StatusServiceUnavailable, "before first check")
+
+	hc.check()
+	assertStatus(healthpb.HealthCheckResponse_NOT_SERVING, http.StatusServiceUnavailable, "storage unavailable")
+
+	hc.check()
+	assertStatus(healthpb.HealthCheckResponse_SERVING, http.StatusOK, "storage available")
+
+	hc.shutdown()
+	hc.check()
+	assertStatus(healthpb.HealthCheckResponse_NOT_SERVING, http.StatusServiceUnavailable, "shutdown")
+
+	_, err := hc.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(t, codes.NotFound, grpc.Code(err))
+
+	storage.AssertExpectations(t)
+}
@@ -1,16 +1,24 @@
 package main
 
 import (
+	"crypto/tls"
 	"errors"
+	"flag"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/piotrkowalczuk/mnemosyne"
 	"github.com/piotrkowalczuk/sklog"
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/grpclog"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 var config configuration
@@ -27,16 +35,16 @@ func main() {
 	config.parse()
 
 	logger := initLogger(config.logger.adapter, config.logger.format, config.logger.level, sklog.KeySubsystem, config.subsystem)
-	postgres := initPostgres(
-		config.storage.postgres.connectionString,
-		logger,
-	)
 
 	hostname, err := os.Hostname()
 	if err != nil {
 		sklog.Fatal(logger, errors.New("mnemosyned: getting hostname failed"))
 	}
 
+	if config.session.ttl > config.session.ttlMax {
+		sklog.Fatal(logger, errors.New("mnemosyned: default session ttl cannot be longer than maximum one"))
+	}
+
 	switch config.monitoring.engine {
 	case "":
 		sklog.Fatal(logger, errors.New("mnemosyned: monitoring is mandatory, at least for now"))
@@ -46,61 +54,239 @@ func main() {
 		sklog.Fatal(logger, errors.New("mnemosyned: unknown monitoring engine"))
 	}
 
+	// Migrations can be managed without starting the server, e.g. mnemosyned -s.engine=postgres migrate status
+	if flag.Arg(0) == "migrate" {
+		if config.storage.engine != storageEnginePostgres {
+			sklog.Fatal(logger, errors.New("mnemosyned: migrations are supported only by postgres storage engine"))
+		}
+
+		postgres := initPostgres(config.storage.postgres.connectionString, logger)
+		defer postgres.Close()
+
+		ps := newPostgresStorage(config.storage.postgres.schema, config.storage.postgres.table, postgres, monitor)
+		runPostgresMigrations(flag.Arg(1), ps.(*postgresStorage), logger)
+		return
+	}
+
 	switch config.storage.engine {
 	case storageEngineInMemory:
-		sklog.Fatal(logger, errors.New("mnemosyned: in memory storage is not implemented yet"))
+		storage = initStorage(initMemoryStorage(config.storage.memory.shards), logger)
 	case storageEnginePostgres:
-		storage = initStorage(initPostgresStorage(config.storage.postgres.tableName, postgres, monitor), logger)
+		postgres := initPostgres(
+			config.storage.postgres.connectionString,
+			logger,
+		)
+		storage = initStorage(initPostgresStorage(config.storage.postgres.schema, config.storage.postgres.table, postgres, monitor), logger)
 	case storageEngineRedis:
-		sklog.Fatal(logger, errors.New("mnemosyned: redis storage is not implemented yet"))
+		redis := initRedis(
+			config.storage.redis.address,
+			config.storage.redis.password,
+			config.storage.redis.database,
+			logger,
+		)
+		storage = initStorage(initRedisStorage(config.storage.redis.prefix, redis, monitor), logger)
 	default:
 		sklog.Fatal(logger, errors.New("mnemosyned: unknown storage engine"))
 	}
 
+	var (
+		events     *eventBus
+		propagated bool
+	)
+	// Events are needed to serve watch calls and to invalidate cache on changes made by other daemons.
+	notify := config.storage.engine == storageEnginePostgres && config.storage.postgres.notify
+	switch {
+	case config.watch.buffer > 0:
+		events = newEventBus(config.watch.buffer)
+	case notify && config.cache.size > 0:
+		events = newEventBus(eventBusBuffer)
+	}
+
+	// Changes made by every daemon (including this one) are delivered by postgres, local events would be duplicated.
+	if events != nil && notify {
+		lst := newPostgresListener(config.storage.postgres.connectionString, storage.(*postgresStorage).channel, events, logger)
+		if err := lst.start(); err != nil {
+			sklog.Fatal(logger, err)
+		}
+		defer lst.stop()
+
+		propagated = true
+	}
+
+	if config.cache.size > 0 {
+		storage = initStorage(initCachedStorage(storage, config.cache.size, config.cache.ttl, monitor.cache), logger)
+
+		// Without propagation, cache is invalidated only by changes made by this daemon.
+		if propagated {
+			cs := storage.(*cachedStorage)
+			cs.start(events)
+			defer cs.stop()
+		}
+	}
+
+	if config.reaper.interval > 0 {
+		if config.reaper.batch <= 0 {
+			sklog.Fatal(logger, errors.New("mnemosyned: reaper batch size needs to be higher than 0"))
+		}
+
+		reaperEvents := events
+		if propagated {
+			reaperEvents = nil
+		}
+
+		rpr := newReaper(logger, storage, monitor.reaper, reaperEvents, config.reaper.interval, config.reaper.batch)
+		rpr.start()
+		defer rpr.stop()
+	}
+
+	if config.monitoring.sessions > 0 {
+		cns := newCensus(logger, storage, monitor.sessions.active, config.monitoring.sessions)
+		cns.start()
+		defer cns.stop()
+	}
+
+	if config.health.interval <= 0 {
+		sklog.Fatal(logger, errors.New("mnemosyned: health check interval needs to be higher than 0"))
+	}
+	health := newHealthChecker(logger, storage, config.health.interval)
+	health.start()
+	defer health.stop()
+
 	listenOn := config.host + ":" + strconv.FormatInt(int64(config.port), 10)
 	listen, err := net.Listen("tcp", listenOn)
 	if err != nil {
 		sklog.Fatal(logger, err)
 	}
 
-	var opts []grpc.ServerOption
-	//	if *tls {
-	//		creds, err := credentials.NewServerTLSFromFile(*certFile, *keyFile)
-	//		if err != nil {
-	//			grpclog.Fatalf("Failed to generate credentials %v", err)
-	//		}
-	//		opts = []grpc.ServerOption{grpc.Creds(creds)}
-	//	}
+	var (
+		opts      []grpc.ServerOption
+		tlsConfig *tls.Config
+	)
+	if config.tls.enabled {
+		tr, err := newTLSReloader(config.tls.certFile, config.tls.keyFile, config.tls.caFile, logger)
+		if err != nil {
+			sklog.Fatal(logger, err)
+		}
+		if config.tls.reload > 0 {
+			tr.start(config.tls.reload)
+			defer tr.stop()
+		}
+
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tr.config("h2"))))
+		tlsConfig = tr.config("h2", "http/1.1")
+	}
+
+	var auth *authorizer
+	if config.auth.file != "" {
+		if auth, err = initAuthorizer(config.auth.file)(); err != nil {
+			sklog.Fatal(logger, err)
+		}
+
+		sklog.Info(logger, "authorization is enabled", "file", config.auth.file)
+	}
+
 	grpclog.SetLogger(sklog.NewGRPCLogger(logger))
 	gRPCServer := grpc.NewServer(opts...)
 
 	mnemosyneServer := &rpcServer{
 		alloc: struct {
-			abandon  handlerFunc
-			context  handlerFunc
-			delete   handlerFunc
-			exists   handlerFunc
-			get      handlerFunc
-			list     handlerFunc
-			setValue handlerFunc
-			start    handlerFunc
+			abandon     handlerFunc
+			abandonAll  handlerFunc
+			clear       handlerFunc
+			context     handlerFunc
+			delete      handlerFunc
+			deleteValue handlerFunc
+			exists      handlerFunc
+			get         handlerFunc
+			list        handlerFunc
+			setValue    handlerFunc
+			start       handlerFunc
+			touch       handlerFunc
+			watch       handlerFunc
 		}{
-			abandon:  newHandlerFunc("abandon"),
-			context:  newHandlerFunc("context"),
-			delete:   newHandlerFunc("delete"),
-			exists:   newHandlerFunc("exists"),
-			get:      newHandlerFunc("get"),
-			list:     newHandlerFunc("list"),
-			setValue: newHandlerFunc("set_value"),
-			start:    newHandlerFunc("start"),
+			abandon:     newHandlerFunc("abandon"),
+			abandonAll:  newHandlerFunc("abandon_all"),
+			clear:       newHandlerFunc("clear"),
+			context:     newHandlerFunc("context"),
+			delete:      newHandlerFunc("delete"),
+			deleteValue: newHandlerFunc("delete_value"),
+			exists:      newHandlerFunc("exists"),
+			get:         newHandlerFunc("get"),
+			list:        newHandlerFunc("list"),
+			setValue:    newHandlerFunc("set_value"),
+			start:       newHandlerFunc("start"),
+			touch:       newHandlerFunc("touch"),
+			watch:       newHandlerFunc("watch"),
 		},
 		logger:  logger,
 		storage: storage,
 		monitor: monitor,
+		opts: handlerOpts{
+			ttl:        config.session.ttl,
+			ttlMax:     config.session.ttlMax,
+			touch:      config.session.touch,
+			events:     events,
+			watch:      config.watch.buffer > 0,
+			propagated: propagated,
+			authorizer: auth,
+		},
 	}
 	mnemosyne.RegisterRPCServer(gRPCServer, mnemosyneServer)
+	healthpb.RegisterHealthServer(gRPCServer, health)
 
-	sklog.Info(logger, "rpc api is running", "host", config.host, "port", config.port, "subsystem", config.subsystem, "namespace", config.namespace)
+	var gateway *http.Server
+	if config.gateway.port > 0 {
+		gatewayOn := config.host + ":" + strconv.FormatInt(int64(config.gateway.port), 10)
+		gatewayListen, err := net.Listen("tcp", gatewayOn)
+		if err != nil {
+			sklog.Fatal(logger, err)
+		}
+		if tlsConfig != nil {
+			gatewayListen = tls.NewListener(gatewayListen, tlsConfig)
+		}
 
-	gRPCServer.Serve(listen)
+		gateway = &http.Server{Handler: newGateway(mnemosyneServer, logger)}
+		go func() {
+			if err := gateway.Serve(gatewayListen); err != nil && err != http.ErrServerClosed {
+				sklog.Fatal(logger, err)
+			}
+		}()
+
+		sklog.Info(logger, "http gateway is running", "host", config.host, "port", config.gateway.port)
+	}
+
+	if config.debug.port > 0 {
+		debugOn := config.host + ":" + strconv.FormatInt(int64(config.debug.port), 10)
+		dbg, err := newDebugServer(debugOn, config.debug.profiling, health, logger)
+		if err != nil {
+			sklog.Fatal(logger, err)
+		}
+
+		dbg.start()
+		defer func() {
+			if err := dbg.stop(); err != nil {
+				sklog.Error(logger, err)
+			}
+		}()
+	}
+
+	// On signal server is reported as not serving first, so load balancers have time to notice it.
+	// Then it stops accepting new calls, deferred functions run after in-flight ones finish or grace period passes.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		sklog.Info(logger, "shutting down", "signal", sig.String(), "drain", config.health.drain)
+
+		health.shutdown()
+		time.Sleep(config.health.drain)
+
+		shutdown(logger, gRPCServer, gateway, events, config.health.grace)
+	}()
+
+	sklog.Info(logger, "rpc api is running", "host", config.host, "port", config.port, "subsystem", config.subsystem, "namespace", config.namespace, "tls", config.tls.enabled)
+
+	if err := gRPCServer.Serve(listen); err != nil {
+		sklog.Error(logger, err)
+	}
 }
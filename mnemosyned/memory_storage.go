@@ -0,0 +1,419 @@
+package main
+
+import (
+	"errors"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/piotrkowalczuk/protot"
+)
+
+const (
+	memoryStorageShards = 32
+)
+
+type memoryStorage struct {
+	shards    []*memoryShard
+	generator mnemosyne.RandomBytesGenerator
+}
+
+type memoryShard struct {
+	sync.RWMutex
+	entries map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	token     mnemosyne.Token
+	subjectID string
+	bag       bagpack
+	expireAt  time.Time
+}
+
+func newMemoryStorage(shards int) Storage {
+	ms := &memoryStorage{
+		shards:    make([]*memoryShard, shards),
+		generator: &mnemosyne.SystemRandomBytesGenerator{},
+	}
+	for i := range ms.shards {
+		ms.shards[i] = &memoryShard{
+			entries: make(map[string]*memoryEntry),
+		}
+	}
+
+	return ms
+}
+
+func initMemoryStorage(shards int) func() (Storage, error) {
+	return func() (Storage, error) {
+		if shards <= 0 {
+			return nil, errors.New("mnemosyned: number of memory storage shards needs to be higher than 0")
+		}
+
+		return newMemoryStorage(shards), nil
+	}
+}
+
+// Start implements Storage interface.
+func (ms *memoryStorage) Start(subjectID string, bag map[string]string, ttl time.Duration) (*mnemosyne.Session, error) {
+	token, err := mnemosyne.RandomToken(ms.generator, tmpKey)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &memoryEntry{
+		token:     token,
+		subjectID: subjectID,
+		bag:       copyBag(bag),
+		expireAt:  time.Now().Add(ttl),
+	}
+
+	shard := ms.shard(&token)
+	shard.Lock()
+	shard.entries[token.Encode()] = entry
+	shard.Unlock()
+
+	return entry.session(), nil
+}
+
+// Get implements Storage interface.
+func (ms *memoryStorage) Get(token *mnemosyne.Token) (*mnemosyne.Session, error) {
+	shard := ms.shard(token)
+	shard.RLock()
+	defer shard.RUnlock()
+
+	entry, ok := shard.entries[token.Encode()]
+	if !ok || entry.expired() {
+		return nil, errSessionNotFound
+	}
+
+	return entry.session(), nil
+}
+
+// List implements Storage interface.
+func (ms *memoryStorage) List(pageToken string, limit int64, subjectID, bagKey string, expiredAtFrom, expiredAtTo *time.Time) ([]*mnemosyne.Session, error) {
+	if limit == 0 {
+		return nil, errors.New("mnemosyned: cannot retrieve list of sessions, limit needs to be higher than 0")
+	}
+
+	after, err := decodeCursor(pageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries are copied while shard is locked, so they can be sorted safely afterwards.
+	var entries []*memoryEntry
+	for _, shard := range ms.shards {
+		shard.RLock()
+		for _, entry := range shard.entries {
+			if subjectID != "" && entry.subjectID != subjectID {
+				continue
+			}
+			if bagKey != "" && !entry.bag.Has(bagKey) {
+				continue
+			}
+			if after != nil && after.before(entry.expireAt, entry.token) {
+				continue
+			}
+			if entry.within(expiredAtFrom, expiredAtTo) {
+				entries = append(entries, entry.snapshot())
+			}
+		}
+		shard.RUnlock()
+	}
+
+	sort.Sort(memoryEntriesByExpireAt(entries))
+
+	if limit < int64(len(entries)) {
+		entries = entries[:limit]
+	}
+
+	sessions := make([]*mnemosyne.Session, 0, len(entries))
+	for _, entry := range entries {
+		sessions = append(sessions, entry.session())
+	}
+
+	return sessions, nil
+}
+
+// Exists implements Storage interface.
+func (ms *memoryStorage) Exists(token *mnemosyne.Token) (bool, error) {
+	shard := ms.shard(token)
+	shard.RLock()
+	defer shard.RUnlock()
+
+	entry, ok := shard.entries[token.Encode()]
+
+	return ok && !entry.expired(), nil
+}
+
+// Abandon implements Storage interface.
+func (ms *memoryStorage) Abandon(token *mnemosyne.Token) (*mnemosyne.Session, error) {
+	shard := ms.shard(token)
+	shard.Lock()
+	defer shard.Unlock()
+
+	key := token.Encode()
+	entry, ok := shard.entries[key]
+	if !ok {
+		return nil, errSessionNotFound
+	}
+
+	delete(shard.entries, key)
+
+	return entry.session(), nil
+}
+
+// AbandonAll implements Storage interface.
+func (ms *memoryStorage) AbandonAll(subjectID string, except *mnemosyne.Token) ([]*mnemosyne.Session, error) {
+	var (
+		abandoned []*mnemosyne.Session
+		exceptKey string
+	)
+	if except != nil {
+		exceptKey = except.Encode()
+	}
+
+	for _, shard := range ms.shards {
+		shard.Lock()
+		for key, entry := range shard.entries {
+			if entry.subjectID != subjectID || key == exceptKey {
+				continue
+			}
+
+			delete(shard.entries, key)
+			abandoned = append(abandoned, entry.session())
+		}
+		shard.Unlock()
+	}
+
+	return abandoned, nil
+}
+
+// Touch implements Storage interface.
+func (ms *memoryStorage) Touch(token *mnemosyne.Token, ttl time.Duration) (*mnemosyne.Session, error) {
+	shard := ms.shard(token)
+	shard.Lock()
+	defer shard.Unlock()
+
+	entry, ok := shard.entries[token.Encode()]
+	if !ok || entry.expired() {
+		return nil, errSessionNotFound
+	}
+
+	entry.expireAt = time.Now().Add(ttl)
+
+	return entry.session(), nil
+}
+
+// SetValue implements Storage interface.
+func (ms *memoryStorage) SetValue(token *mnemosyne.Token, key, value string) (*mnemosyne.Session, error) {
+	shard := ms.shard(token)
+	shard.Lock()
+	defer shard.Unlock()
+
+	entry, ok := shard.entries[token.Encode()]
+	if !ok || entry.expired() {
+		return nil, errSessionNotFound
+	}
+
+	entry.bag.Set(key, value)
+
+	return entry.session(), nil
+}
+
+// DeleteValue implements Storage interface.
+func (ms *memoryStorage) DeleteValue(token *mnemosyne.Token, key string) (*mnemosyne.Session, error) {
+	shard := ms.shard(token)
+	shard.Lock()
+	defer shard.Unlock()
+
+	entry, ok := shard.entries[token.Encode()]
+	if !ok || entry.expired() {
+		return nil, errSessionNotFound
+	}
+
+	entry.bag.Del(key)
+
+	return entry.session(), nil
+}
+
+// Clear implements Storage interface.
+func (ms *memoryStorage) Clear(token *mnemosyne.Token) (*mnemosyne.Session, error) {
+	shard := ms.shard(token)
+	shard.Lock()
+	defer shard.Unlock()
+
+	entry, ok := shard.entries[token.Encode()]
+	if !ok || entry.expired() {
+		return nil, errSessionNotFound
+	}
+
+	entry.bag = bagpack{}
+
+	return entry.session(), nil
+}
+
+// Delete implements Storage interface.
+func (ms *memoryStorage) Delete(token *mnemosyne.Token, expiredAtFrom, expiredAtTo *time.Time) ([]*mnemosyne.Session, error) {
+	if token == nil && expiredAtFrom == nil && expiredAtTo == nil {
+		return nil, errors.New("mnemosyned: session cannot be deleted, no where parameter provided")
+	}
+
+	var (
+		deleted  []*mnemosyne.Session
+		tokenKey string
+	)
+	shards := ms.shards
+	if token != nil {
+		tokenKey = token.Encode()
+		shards = []*memoryShard{ms.shard(token)}
+	}
+
+	for _, shard := range shards {
+		shard.Lock()
+		for key, entry := range shard.entries {
+			if token != nil && key != tokenKey {
+				continue
+			}
+			if !entry.within(expiredAtFrom, expiredAtTo) {
+				continue
+			}
+
+			delete(shard.entries, key)
+			deleted = append(deleted, entry.session())
+		}
+		shard.Unlock()
+	}
+
+	return deleted, nil
+}
+
+// Purge implements Storage interface.
+func (ms *memoryStorage) Purge(limit int64) ([]*mnemosyne.Session, error) {
+	var purged []*mnemosyne.Session
+
+	for _, shard := range ms.shards {
+		shard.Lock()
+		for key, entry := range shard.entries {
+			if int64(len(purged)) >= limit {
+				break
+			}
+			if !entry.expired() {
+				continue
+			}
+
+			delete(shard.entries, key)
+			purged = append(purged, entry.session())
+		}
+		shard.Unlock()
+
+		if int64(len(purged)) >= limit {
+			break
+		}
+	}
+
+	return purged, nil
+}
+
+// Count implements Storage interface.
+func (ms *memoryStorage) Count() (int64, error) {
+	var count int64
+
+	for _, shard := range ms.shards {
+		shard.RLock()
+		for _, entry := range shard.entries {
+			if !entry.expired() {
+				count++
+			}
+		}
+		shard.RUnlock()
+	}
+
+	return count, nil
+}
+
+// Ping implements Storage interface.
+func (ms *memoryStorage) Ping() error {
+	return nil
+}
+
+// Setup implements Storage interface.
+func (ms *memoryStorage) Setup() error {
+	return nil
+}
+
+// TearDown implements Storage interface.
+func (ms *memoryStorage) TearDown() error {
+	for _, shard := range ms.shards {
+		shard.Lock()
+		shard.entries = make(map[string]*memoryEntry)
+		shard.Unlock()
+	}
+
+	return nil
+}
+
+func (ms *memoryStorage) shard(token *mnemosyne.Token) *memoryShard {
+	h := fnv.New32a()
+	h.Write(token.Hash)
+
+	return ms.shards[h.Sum32()%uint32(len(ms.shards))]
+}
+
+func (me *memoryEntry) within(expiredAtFrom, expiredAtTo *time.Time) bool {
+	if expiredAtFrom != nil && !me.expireAt.After(*expiredAtFrom) {
+		return false
+	}
+	if expiredAtTo != nil && !me.expireAt.Before(*expiredAtTo) {
+		return false
+	}
+
+	return true
+}
+
+func (me *memoryEntry) expired() bool {
+	return !me.expireAt.After(time.Now())
+}
+
+// snapshot returns a copy of the entry that does not share the bag with the original, caller must hold the shard lock.
+func (me *memoryEntry) snapshot() *memoryEntry {
+	cp := *me
+	cp.bag = copyBag(me.bag)
+
+	return &cp
+}
+
+func (me *memoryEntry) session() *mnemosyne.Session {
+	token := me.token
+
+	return &mnemosyne.Session{
+		Token:     &token,
+		SubjectId: me.subjectID,
+		Bag:       copyBag(me.bag),
+		ExpireAt:  protot.TimeToTimestamp(me.expireAt),
+	}
+}
+
+type memoryEntriesByExpireAt []*memoryEntry
+
+func (m memoryEntriesByExpireAt) Len() int      { return len(m) }
+func (m memoryEntriesByExpireAt) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+func (m memoryEntriesByExpireAt) Less(i, j int) bool {
+	if m[i].expireAt.Equal(m[j].expireAt) {
+		return m[i].token.Encode() < m[j].token.Encode()
+	}
+
+	return m[i].expireAt.Before(m[j].expireAt)
+}
+
+func copyBag(bag map[string]string) bagpack {
+	cp := make(bagpack, len(bag))
+	for key, value := range bag {
+		cp[key] = value
+	}
+
+	return cp
+}
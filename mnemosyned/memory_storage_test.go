@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+var (
+	memoryStore = newMemoryStorage(memoryStorageShards)
+)
+
+func TestMemoryStorage_Start(t *testing.T) {
+	testStorage_Start(t, memoryStore)
+}
+
+func TestMemoryStorage_Get(t *testing.T) {
+	testStorage_Get(t, memoryStore)
+}
+
+func TestMemoryStorage_List(t *testing.T) {
+	testStorage_List(t, memoryStore)
+}
+
+func TestMemoryStorage_Exists(t *testing.T) {
+	testStorage_Exists(t, memoryStore)
+}
+
+func TestMemoryStorage_Abandon(t *testing.T) {
+	testStorage_Abandon(t, memoryStore)
+}
+
+func TestMemoryStorage_AbandonAll(t *testing.T) {
+	testStorage_AbandonAll(t, memoryStore)
+}
+
+func TestMemoryStorage_Touch(t *testing.T) {
+	testStorage_Touch(t, memoryStore)
+}
+
+func TestMemoryStorage_SetValue(t *testing.T) {
+	testStorage_SetValue(t, memoryStore)
+}
+
+func TestMemoryStorage_DeleteValue(t *testing.T) {
+	testStorage_DeleteValue(t, memoryStore)
+}
+
+func TestMemoryStorage_Clear(t *testing.T) {
+	testStorage_Clear(t, memoryStore)
+}
+
+func TestMemoryStorage_Delete(t *testing.T) {
+	testStorage_Delete(t, memoryStore)
+}
+
+func TestMemoryStorage_Purge(t *testing.T) {
+	testStorage_Purge(t, memoryStore)
+}
+
+func TestMemoryStorage_Count(t *testing.T) {
+	testStorage_Count(t, memoryStore)
+}
+
+func TestMemoryStorage_List_concurrentModification(t *testing.T) {
+	s := newMemoryStorage(1)
+	ses, err := s.Start("subjectID", map[string]string{"key": "value"}, ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			s.SetValue(ses.Token, "key", strconv.Itoa(i))
+			s.Touch(ses.Token, ttl)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if _, err := s.List("", 10, "", "", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
@@ -6,22 +6,57 @@ var (
 	monitoringRPCLabels = []string{
 		"method",
 	}
+	monitoringRPCErrorLabels = []string{
+		"method",
+		"code",
+	}
 	monitoringPostgresLabels = []string{
 		"query",
 	}
+	monitoringRedisLabels = []string{
+		"command",
+	}
 )
 
 type monitoring struct {
 	rpc      monitoringRPC
 	postgres monitoringPostgres
+	redis    monitoringRedis
+	reaper   monitoringReaper
+	cache    monitoringCache
+	sessions monitoringSessions
 }
 
+// monitoringRPC durations are observed in microseconds.
 type monitoringRPC struct {
 	requests metrics.Counter
 	errors   metrics.Counter
+	duration metrics.Histogram
 }
 
+// monitoringPostgres queries are labeled by short name of the operation instead of SQL text.
 type monitoringPostgres struct {
-	queries metrics.Counter
-	errors  metrics.Counter
+	queries  metrics.Counter
+	errors   metrics.Counter
+	duration metrics.Histogram
+}
+
+type monitoringRedis struct {
+	commands metrics.Counter
+	errors   metrics.Counter
+	duration metrics.Histogram
+}
+
+type monitoringReaper struct {
+	purged metrics.Counter
+	errors metrics.Counter
+}
+
+type monitoringCache struct {
+	hits   metrics.Counter
+	misses metrics.Counter
+}
+
+type monitoringSessions struct {
+	active metrics.Gauge
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/lib/pq"
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/piotrkowalczuk/protot"
+	"github.com/piotrkowalczuk/sklog"
+)
+
+const (
+	postgresListenerMinReconnect = 10 * time.Second
+	postgresListenerMaxReconnect = time.Minute
+	postgresListenerPing         = 90 * time.Second
+)
+
+// postgresListener receives notifications about session changes made by any daemon connected to the same table
+// and publishes them as events.
+type postgresListener struct {
+	logger   log.Logger
+	listener *pq.Listener
+	channel  string
+	events   *eventBus
+	done     chan struct{}
+}
+
+// postgresNotification is a payload sent by the trigger created within session table migration.
+// Bag is not included, notification payload size is limited.
+type postgresNotification struct {
+	Type      string    `json:"type"`
+	Token     string    `json:"token"`
+	SubjectID string    `json:"subject_id"`
+	ExpireAt  time.Time `json:"expire_at"`
+}
+
+func newPostgresListener(connectionString, channel string, events *eventBus, logger log.Logger) *postgresListener {
+	logger = log.NewContext(logger).With("channel", channel)
+
+	return &postgresListener{
+		logger: logger,
+		listener: pq.NewListener(connectionString, postgresListenerMinReconnect, postgresListenerMaxReconnect, func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				sklog.Error(logger, err)
+			}
+		}),
+		channel: channel,
+		events:  events,
+		done:    make(chan struct{}),
+	}
+}
+
+// start subscribes to the channel and runs notification loop in separate goroutine.
+func (pl *postgresListener) start() error {
+	if err := pl.listener.Listen(pl.channel); err != nil {
+		return err
+	}
+
+	go pl.run()
+
+	sklog.Info(pl.logger, "postgres listener has been started")
+
+	return nil
+}
+
+// stop terminates notification loop and closes the connection.
+func (pl *postgresListener) stop() error {
+	close(pl.done)
+
+	return pl.listener.Close()
+}
+
+func (pl *postgresListener) run() {
+	ticker := time.NewTicker(postgresListenerPing)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case n := <-pl.listener.Notify:
+			// Nil notification is sent after reconnect, notifications sent in the meantime are lost.
+			if n == nil {
+				sklog.Info(pl.logger, "postgres listener has been reconnected")
+				continue
+			}
+
+			typ, ses, err := decodePostgresNotification(n.Extra)
+			if err != nil {
+				sklog.Error(pl.logger, err)
+				continue
+			}
+
+			pl.events.publish(typ, ses)
+		case <-ticker.C:
+			go pl.listener.Ping()
+		case <-pl.done:
+			return
+		}
+	}
+}
+
+func decodePostgresNotification(payload string) (mnemosyne.Event_Type, *mnemosyne.Session, error) {
+	var pn postgresNotification
+	if err := json.Unmarshal([]byte(payload), &pn); err != nil {
+		return mnemosyne.Event_UNKNOWN, nil, err
+	}
+
+	typ, ok := mnemosyne.Event_Type_value[pn.Type]
+	if !ok || typ == int32(mnemosyne.Event_UNKNOWN) {
+		return mnemosyne.Event_UNKNOWN, nil, errors.New("mnemosyned: postgres notification of unknown type")
+	}
+
+	raw, err := hex.DecodeString(pn.Token)
+	if err != nil {
+		return mnemosyne.Event_UNKNOWN, nil, err
+	}
+	token := mnemosyne.DecodeToken(raw)
+
+	return mnemosyne.Event_Type(typ), &mnemosyne.Session{
+		Token:     &token,
+		SubjectId: pn.SubjectID,
+		ExpireAt:  protot.TimeToTimestamp(pn.ExpireAt),
+	}, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodePostgresNotification(t *testing.T) {
+	token := mnemosyne.NewToken([]byte("0000000001"), []byte("hash"))
+	payload := `{"type" : "ABANDONED", "token" : "` + hex.EncodeToString(token.Bytes()) + `", "subject_id" : "subject", "expire_at" : "2016-08-01T12:00:00.123456+02:00"}`
+
+	typ, ses, err := decodePostgresNotification(payload)
+	require.NoError(t, err)
+	assert.Equal(t, mnemosyne.Event_ABANDONED, typ)
+	assert.Equal(t, token.Encode(), ses.Token.Encode())
+	assert.Equal(t, "subject", ses.SubjectId)
+	assert.True(t, time.Date(2016, 8, 1, 10, 0, 0, 123456000, time.UTC).Equal(ses.ExpireAt.Time()))
+	assert.Nil(t, ses.Bag)
+}
+
+func TestDecodePostgresNotification_invalid(t *testing.T) {
+	for _, payload := range []string{
+		`not json`,
+		`{"type" : "UNKNOWN", "token" : "00"}`,
+		`{"type" : "REMOVED", "token" : "00"}`,
+		`{"type" : "CREATED", "token" : "not hex"}`,
+	} {
+		_, _, err := decodePostgresNotification(payload)
+		assert.Error(t, err, "payload: %s", payload)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/lib/pq"
+	"github.com/piotrkowalczuk/sklog"
+)
+
+const (
+	postgresMigrationUp     = "up"
+	postgresMigrationStatus = "status"
+	// postgresMigrationBatch is number of rows rewritten at once by data migrations.
+	postgresMigrationBatch = 1000
+
+	postgresVersionTable = `
+		CREATE SCHEMA IF NOT EXISTS %[1]s;
+		CREATE TABLE IF NOT EXISTS %[2]s (
+			version BIGINT PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at timestamp with time zone NOT NULL DEFAULT NOW()
+		)
+	`
+)
+
+// postgresMigration is a single schema change.
+// Placeholders %[1]s and %[2]s within up query are replaced by schema and session table name respectively.
+// Data that cannot be migrated by plain SQL is rewritten by optional backfill, within the same transaction.
+type postgresMigration struct {
+	version     int64
+	description string
+	up          string
+	backfill    func(tx *sql.Tx, table string) error
+}
+
+// postgresMigrations needs to be ordered by version. Once released, migration cannot be modified.
+var postgresMigrations = []postgresMigration{
+	{
+		version:     1,
+		description: "create session table",
+		up: `
+			CREATE SCHEMA IF NOT EXISTS %[1]s;
+			CREATE TABLE IF NOT EXISTS %[2]s (
+				token BYTEA PRIMARY KEY,
+				subject_id TEXT NOT NULL,
+				bag bytea NOT NULL,
+				expire_at timestamp with time zone NOT NULL
+			)
+		`,
+	},
+	{
+		version:     2,
+		description: "index session subject and expiration time",
+		up: `
+			CREATE INDEX ON %[2]s (subject_id);
+			CREATE INDEX ON %[2]s (expire_at);
+		`,
+	},
+	{
+		// Bag is gob encoded, so keys of existing sessions are extracted by the daemon itself.
+		version:     3,
+		description: "track session bag keys",
+		up: `
+			ALTER TABLE %[2]s ADD COLUMN bag_keys JSONB NOT NULL DEFAULT '[]';
+			CREATE INDEX ON %[2]s USING GIN (bag_keys);
+		`,
+		backfill: postgresBackfillBagKeys,
+	},
+	{
+		// Channel is named after the table, it can be listened by every daemon connected to the same database.
+		version:     4,
+		description: "notify about session changes",
+		up: `
+			CREATE OR REPLACE FUNCTION %[1]s.notify_session_change() RETURNS TRIGGER AS $$
+			DECLARE
+				entry RECORD;
+				kind TEXT;
+			BEGIN
+				CASE TG_OP
+				WHEN 'INSERT' THEN
+					entry := NEW;
+					kind := 'CREATED';
+				WHEN 'UPDATE' THEN
+					entry := NEW;
+					kind := 'UPDATED';
+				ELSE
+					entry := OLD;
+					kind := CASE WHEN OLD.expire_at <= NOW() THEN 'EXPIRED' ELSE 'ABANDONED' END;
+				END CASE;
+
+				PERFORM pg_notify(TG_TABLE_SCHEMA || '.' || TG_TABLE_NAME, json_build_object(
+					'type', kind,
+					'token', encode(entry.token, 'hex'),
+					'subject_id', entry.subject_id,
+					'expire_at', entry.expire_at
+				)::TEXT);
+
+				RETURN NULL;
+			END;
+			$$ LANGUAGE plpgsql;
+			CREATE TRIGGER notify_session_change AFTER INSERT OR UPDATE OR DELETE ON %[2]s
+				FOR EACH ROW EXECUTE PROCEDURE %[1]s.notify_session_change();
+		`,
+	},
+}
+
+// postgresBackfillBagKeys sets bag keys of every existing session, batch by batch in token order.
+func postgresBackfillBagKeys(tx *sql.Tx, table string) error {
+	// Empty token precedes every other one, NULL would not match anything.
+	after := []byte{}
+	for {
+		rows, err := tx.Query(`SELECT token, bag FROM `+table+` WHERE token > $1 ORDER BY token LIMIT $2`, after, postgresMigrationBatch)
+		if err != nil {
+			return err
+		}
+
+		var (
+			tokens [][]byte
+			bags   []bagpack
+		)
+		for rows.Next() {
+			var (
+				token []byte
+				bag   bagpack
+			)
+			if err = rows.Scan(&token, &bag); err != nil {
+				rows.Close()
+				return err
+			}
+			tokens = append(tokens, token)
+			bags = append(bags, bag)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		for i, token := range tokens {
+			if _, err = tx.Exec(`UPDATE `+table+` SET bag_keys = $2 WHERE token = $1`, token, bagpackKeys(bags[i])); err != nil {
+				return err
+			}
+		}
+
+		if len(tokens) < postgresMigrationBatch {
+			return nil
+		}
+		after = tokens[len(tokens)-1]
+	}
+}
+
+type postgresMigrationState struct {
+	version     int64
+	description string
+	appliedAt   *time.Time
+}
+
+// migrate applies all pending migrations within single transaction.
+// Transaction level advisory lock prevents concurrent daemons from migrating the same table at once.
+func (ps *postgresStorage) migrate() (applied int, err error) {
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if _, err = tx.Exec(`SELECT pg_advisory_xact_lock($1)`, ps.lockID()); err != nil {
+		return
+	}
+	if _, err = tx.Exec(fmt.Sprintf(postgresVersionTable, ps.schema, ps.versionTable)); err != nil {
+		return
+	}
+
+	var current int64
+	if err = tx.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM ` + ps.versionTable).Scan(&current); err != nil {
+		return
+	}
+
+	for _, m := range postgresMigrations {
+		if m.version <= current {
+			continue
+		}
+		if _, err = tx.Exec(fmt.Sprintf(m.up, ps.schema, ps.table)); err != nil {
+			return applied, fmt.Errorf("mnemosyned: migration %d (%s) failure: %s", m.version, m.description, err.Error())
+		}
+		if m.backfill != nil {
+			if err = m.backfill(tx, ps.table); err != nil {
+				return applied, fmt.Errorf("mnemosyned: migration %d (%s) backfill failure: %s", m.version, m.description, err.Error())
+			}
+		}
+		if _, err = tx.Exec(`INSERT INTO `+ps.versionTable+` (version, description) VALUES ($1, $2)`, m.version, m.description); err != nil {
+			return
+		}
+		applied++
+	}
+
+	return
+}
+
+// migrations returns state of every known migration, without applying any of them.
+func (ps *postgresStorage) migrations() ([]postgresMigrationState, error) {
+	states := make([]postgresMigrationState, 0, len(postgresMigrations))
+	applied := make(map[int64]time.Time, len(postgresMigrations))
+
+	rows, err := ps.db.Query(`SELECT version, applied_at FROM ` + ps.versionTable)
+	if err != nil {
+		// Version table does not exists yet, nothing has been applied.
+		if e, ok := err.(*pq.Error); !ok || e.Code != "42P01" {
+			return nil, err
+		}
+	} else {
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				version   int64
+				appliedAt time.Time
+			)
+			if err = rows.Scan(&version, &appliedAt); err != nil {
+				return nil, err
+			}
+			applied[version] = appliedAt
+		}
+		if rows.Err() != nil {
+			return nil, rows.Err()
+		}
+	}
+
+	for _, m := range postgresMigrations {
+		state := postgresMigrationState{
+			version:     m.version,
+			description: m.description,
+		}
+		if appliedAt, ok := applied[m.version]; ok {
+			state.appliedAt = &appliedAt
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+func (ps *postgresStorage) lockID() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(ps.table))
+
+	return int64(h.Sum64())
+}
+
+func runPostgresMigrations(mode string, ps *postgresStorage, logger log.Logger) {
+	switch mode {
+	case postgresMigrationUp:
+		applied, err := ps.migrate()
+		if err != nil {
+			sklog.Fatal(logger, err)
+		}
+
+		sklog.Info(logger, "postgres migrations have been applied", "applied", applied)
+	case postgresMigrationStatus:
+		states, err := ps.migrations()
+		if err != nil {
+			sklog.Fatal(logger, err)
+		}
+
+		for _, state := range states {
+			if state.appliedAt == nil {
+				sklog.Info(logger, "postgres migration is pending", "version", state.version, "description", state.description)
+				continue
+			}
+			sklog.Info(logger, "postgres migration is applied", "version", state.version, "description", state.description, "applied_at", state.appliedAt.Format(time.RFC3339))
+		}
+	default:
+		sklog.Fatal(logger, errors.New("mnemosyned: unknown migration mode, expected up or status"))
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresMigrations(t *testing.T) {
+	var previous int64
+	for _, m := range postgresMigrations {
+		assert.True(t, m.version > previous, "migration %d should be placed before %d", m.version, previous)
+		assert.NotEmpty(t, m.description, "migration %d is missing description", m.version)
+		assert.NotEmpty(t, m.up, "migration %d is missing query", m.version)
+
+		previous = m.version
+	}
+}
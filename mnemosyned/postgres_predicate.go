@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/piotrkowalczuk/mnemosyne"
+)
+
+// postgresPredicate builds WHERE clause of queries against session table.
+// Placeholders are numbered continuously, after arguments that are already used by the query.
+// Every filter that is not provided (nil or empty) is skipped.
+type postgresPredicate struct {
+	conditions []string
+	args       []interface{}
+}
+
+func newPostgresPredicate(args ...interface{}) *postgresPredicate {
+	return &postgresPredicate{
+		args: args,
+	}
+}
+
+func (pp *postgresPredicate) token(token *mnemosyne.Token) *postgresPredicate {
+	if token != nil {
+		pp.add("token = %s", *token)
+	}
+
+	return pp
+}
+
+func (pp *postgresPredicate) subject(subjectID string) *postgresPredicate {
+	if subjectID != "" {
+		pp.add("subject_id = %s", subjectID)
+	}
+
+	return pp
+}
+
+// expireAt limits expiration time to given range, both ends are exclusive.
+func (pp *postgresPredicate) expireAt(from, to *time.Time) *postgresPredicate {
+	if from != nil {
+		pp.add("expire_at > %s", *from)
+	}
+	if to != nil {
+		pp.add("expire_at < %s", *to)
+	}
+
+	return pp
+}
+
+func (pp *postgresPredicate) bagKey(key string) *postgresPredicate {
+	if key != "" {
+		pp.add("bag_keys ? %s", key)
+	}
+
+	return pp
+}
+
+// after skips sessions placed before or at the cursor.
+func (pp *postgresPredicate) after(c *cursor) *postgresPredicate {
+	if c != nil {
+		pp.add("(expire_at, token) > (%s, %s)", c.expireAt, c.token)
+	}
+
+	return pp
+}
+
+func (pp *postgresPredicate) empty() bool {
+	return len(pp.conditions) == 0
+}
+
+// where returns WHERE clause preceded by space or empty string if there are no conditions.
+func (pp *postgresPredicate) where() string {
+	if pp.empty() {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(pp.conditions, " AND ")
+}
+
+func (pp *postgresPredicate) add(format string, args ...interface{}) {
+	placeholders := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		pp.args = append(pp.args, arg)
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(pp.args)))
+	}
+
+	pp.conditions = append(pp.conditions, fmt.Sprintf(format, placeholders...))
+}
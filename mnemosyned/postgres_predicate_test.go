@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresPredicate(t *testing.T) {
+	token := mnemosyne.NewToken([]byte("key"), []byte("hash"))
+	from := time.Unix(1470000000, 0)
+	to := time.Unix(1480000000, 0)
+
+	filters := []struct {
+		condition string
+		args      []interface{}
+		apply     func(*postgresPredicate)
+	}{
+		{
+			condition: "token = $%d",
+			args:      []interface{}{token},
+			apply:     func(pp *postgresPredicate) { pp.token(&token) },
+		},
+		{
+			condition: "subject_id = $%d",
+			args:      []interface{}{"subject"},
+			apply:     func(pp *postgresPredicate) { pp.subject("subject") },
+		},
+		{
+			condition: "bag_keys ? $%d",
+			args:      []interface{}{"key"},
+			apply:     func(pp *postgresPredicate) { pp.bagKey("key") },
+		},
+		{
+			condition: "expire_at > $%d",
+			args:      []interface{}{from},
+			apply:     func(pp *postgresPredicate) { pp.expireAt(&from, nil) },
+		},
+		{
+			condition: "expire_at < $%d",
+			args:      []interface{}{to},
+			apply:     func(pp *postgresPredicate) { pp.expireAt(nil, &to) },
+		},
+	}
+
+	// Every combination of filters, with and without arguments already used by the query.
+	for _, preceding := range [][]interface{}{nil, {int64(10)}} {
+		for mask := 0; mask < 1<<uint(len(filters)); mask++ {
+			var (
+				conditions []string
+				args       []interface{}
+			)
+			pp := newPostgresPredicate(preceding...)
+			args = append(args, preceding...)
+
+			for i, f := range filters {
+				if mask&(1<<uint(i)) == 0 {
+					continue
+				}
+
+				f.apply(pp)
+				args = append(args, f.args...)
+				conditions = append(conditions, strings.Replace(f.condition, "%d", strconv.Itoa(len(args)), 1))
+			}
+
+			expected := ""
+			if len(conditions) > 0 {
+				expected = " WHERE " + strings.Join(conditions, " AND ")
+			}
+
+			assert.Equal(t, expected, pp.where(), "mask: %05b, preceding: %v", mask, preceding)
+			assert.Equal(t, len(conditions) == 0, pp.empty(), "mask: %05b, preceding: %v", mask, preceding)
+			assert.EqualValues(t, args, pp.args, "mask: %05b, preceding: %v", mask, preceding)
+		}
+	}
+}
+
+func TestPostgresPredicate_emptyFilters(t *testing.T) {
+	pp := newPostgresPredicate(int64(10)).
+		token(nil).
+		subject("").
+		bagKey("").
+		expireAt(nil, nil).
+		after(nil)
+
+	assert.True(t, pp.empty())
+	assert.Equal(t, "", pp.where())
+	assert.Equal(t, []interface{}{int64(10)}, pp.args)
+}
+
+func TestPostgresPredicate_after(t *testing.T) {
+	token := mnemosyne.NewToken([]byte("key"), []byte("hash"))
+	c := &cursor{expireAt: time.Unix(1470000000, 0), token: token}
+
+	pp := newPostgresPredicate(int64(10)).
+		subject("subject").
+		after(c)
+
+	assert.Equal(t, " WHERE subject_id = $2 AND (expire_at, token) > ($3, $4)", pp.where())
+	assert.Equal(t, []interface{}{int64(10), "subject", c.expireAt, token}, pp.args)
+}
@@ -7,50 +7,54 @@ import (
 	"time"
 
 	"github.com/go-kit/kit/metrics"
+	"github.com/lib/pq"
 	"github.com/piotrkowalczuk/mnemosyne"
 	"github.com/piotrkowalczuk/protot"
 )
 
-const (
-	postgresSchema = `
-		CREATE SCHEMA IF NOT EXISTS mnemosyne;
-		CREATE TABLE IF NOT EXISTS mnemosyne.session (
-			token BYTEA PRIMARY KEY,
-			subject_id TEXT NOT NULL,
-			bag bytea NOT NULL,
-			expire_at timestamp with time zone NOT NULL
-		)
-    `
-)
-
 var (
 	tmpKey = []byte(hex.EncodeToString([]byte("1")))
 )
 
 type postgresStorage struct {
-	db        *sql.DB
-	tableName string
-	generator mnemosyne.RandomBytesGenerator
-	monitor   *monitoring
+	db *sql.DB
+	// schema and tables are quoted identifiers, tables are qualified by the schema.
+	// Notification channel name is not quoted.
+	schema       string
+	table        string
+	versionTable string
+	channel      string
+	generator    mnemosyne.RandomBytesGenerator
+	monitor      *monitoring
 }
 
-func newPostgresStorage(tn string, db *sql.DB, m *monitoring) Storage {
+func newPostgresStorage(schema, table string, db *sql.DB, m *monitoring) Storage {
 	return &postgresStorage{
-		db:        db,
-		tableName: tn,
-		generator: &mnemosyne.SystemRandomBytesGenerator{},
-		monitor:   m,
+		db:           db,
+		schema:       pq.QuoteIdentifier(schema),
+		table:        pq.QuoteIdentifier(schema) + "." + pq.QuoteIdentifier(table),
+		versionTable: pq.QuoteIdentifier(schema) + "." + pq.QuoteIdentifier(table+"_schema_version"),
+		channel:      schema + "." + table,
+		generator:    &mnemosyne.SystemRandomBytesGenerator{},
+		monitor:      m,
 	}
 }
 
-func initPostgresStorage(tn string, db *sql.DB, m *monitoring) func() (Storage, error) {
+func initPostgresStorage(schema, table string, db *sql.DB, m *monitoring) func() (Storage, error) {
 	return func() (Storage, error) {
-		return newPostgresStorage(tn, db, m), nil
+		switch {
+		case schema == "":
+			return nil, errors.New("mnemosyned: postgres schema name cannot be empty")
+		case table == "":
+			return nil, errors.New("mnemosyned: postgres table name cannot be empty")
+		}
+
+		return newPostgresStorage(schema, table, db, m), nil
 	}
 }
 
 // Create implements Storage interface.
-func (ps *postgresStorage) Start(subjectID string, bag map[string]string) (*mnemosyne.Session, error) {
+func (ps *postgresStorage) Start(subjectID string, bag map[string]string, ttl time.Duration) (*mnemosyne.Session, error) {
 	token, err := mnemosyne.RandomToken(ps.generator, tmpKey)
 	if err != nil {
 		return nil, err
@@ -62,31 +66,33 @@ func (ps *postgresStorage) Start(subjectID string, bag map[string]string) (*mnem
 		Bag:       bagpack(bag),
 	}
 
-	if err := ps.save(entity); err != nil {
+	if err := ps.save(entity, ttl); err != nil {
 		return nil, err
 	}
 
 	return newSessionFromSessionEntity(entity), nil
 }
 
-func (ps *postgresStorage) save(entity *sessionEntity) (err error) {
+func (ps *postgresStorage) save(entity *sessionEntity, ttl time.Duration) (err error) {
 	query := `
-		INSERT INTO mnemosyne.session (token, subject_id, bag, expire_at)
-		VALUES ($1, $2, $3, NOW() + '30 minutes'::interval)
+		INSERT INTO ` + ps.table + ` (token, subject_id, bag, bag_keys, expire_at)
+		VALUES ($1, $2, $3, $4, NOW() + $5 * INTERVAL '1 microsecond')
 		RETURNING expire_at
 
 	`
-	field := metrics.Field{Key: "query", Value: query}
 
+	start := time.Now()
 	err = ps.db.QueryRow(
 		query,
 		entity.Token,
 		entity.SubjectID,
 		entity.Bag,
+		bagpackKeys(entity.Bag),
+		int64(ttl/time.Microsecond),
 	).Scan(
 		&entity.ExpireAt,
 	)
-	ps.monitor.postgres.queries.With(field).Add(1)
+	ps.observe("save", start, err)
 
 	return
 }
@@ -96,19 +102,19 @@ func (ps *postgresStorage) Get(token *mnemosyne.Token) (*mnemosyne.Session, erro
 	var entity sessionEntity
 	query := `
 		SELECT subject_id, bag, expire_at
-		FROM mnemosyne.session
-		WHERE token = $1
+		FROM ` + ps.table + `
+		WHERE token = $1 AND expire_at > NOW()
 		LIMIT 1
 	`
-	field := metrics.Field{Key: "query", Value: query}
 
+	start := time.Now()
 	err := ps.db.QueryRow(query, token).Scan(
 		&entity.SubjectID,
 		&entity.Bag,
 		&entity.ExpireAt,
 	)
+	ps.observe("get", start, err)
 	if err != nil {
-		ps.monitor.postgres.errors.With(field).Add(1)
 		if err == sql.ErrNoRows {
 			return nil, errSessionNotFound
 		}
@@ -124,218 +130,330 @@ func (ps *postgresStorage) Get(token *mnemosyne.Token) (*mnemosyne.Session, erro
 }
 
 // List implements Storage interface.
-func (ps *postgresStorage) List(offset, limit int64, expiredAtFrom, expiredAtTo *time.Time) ([]*mnemosyne.Session, error) {
+func (ps *postgresStorage) List(pageToken string, limit int64, subjectID, bagKey string, expiredAtFrom, expiredAtTo *time.Time) ([]*mnemosyne.Session, error) {
 	if limit == 0 {
 		return nil, errors.New("mnemosyned: cannot retrieve list of sessions, limit needs to be higher than 0")
 	}
 
-	args := []interface{}{offset, limit}
-	query := "SELECT token, subject_id, bag, expire_at FROM mnemosyne.session"
-
-	switch {
-	case expiredAtFrom != nil && expiredAtTo == nil:
-		query += "expire_at > $3"
-		args = append(args, expiredAtFrom)
-	case expiredAtFrom == nil && expiredAtTo != nil:
-		query += "expire_at < $3"
-		args = append(args, expiredAtTo)
-	case expiredAtFrom != nil && expiredAtTo != nil:
-		query += "expire_at > $4 AND expire_at < $5"
-		args = append(args, expiredAtFrom, expiredAtTo)
+	after, err := decodeCursor(pageToken)
+	if err != nil {
+		return nil, err
 	}
 
-	query += " OFFSET $1 LIMIT $2"
+	predicate := newPostgresPredicate(limit).
+		subject(subjectID).
+		bagKey(bagKey).
+		expireAt(expiredAtFrom, expiredAtTo).
+		after(after)
+	query := "SELECT token, subject_id, bag, expire_at FROM " + ps.table + predicate.where() + " ORDER BY expire_at, token LIMIT $1"
 
-	field := metrics.Field{Key: "query", Value: query}
+	return ps.sessions("list", query, predicate.args...)
+}
 
-	rows, err := ps.db.Query(query, args...)
-	if err != nil {
-		ps.monitor.postgres.errors.With(field).Add(1)
-		return nil, err
-	}
-	defer rows.Close()
+// Exists implements Storage interface.
+func (ps *postgresStorage) Exists(token *mnemosyne.Token) (exists bool, err error) {
+	query := `SELECT EXISTS(SELECT 1 FROM ` + ps.table + ` WHERE token = $1 AND expire_at > NOW())`
 
-	ps.monitor.postgres.queries.With(field).Add(1)
+	start := time.Now()
+	err = ps.db.QueryRow(query, *token).Scan(
+		&exists,
+	)
+	ps.observe("exists", start, err)
 
-	sessions := make([]*mnemosyne.Session, 0, limit)
-	for rows.Next() {
-		var entity sessionEntity
+	return
+}
 
-		err = rows.Scan(
-			&entity.Token,
-			&entity.SubjectID,
-			&entity.Bag,
-			&entity.ExpireAt,
-		)
-		if err != nil {
-			ps.monitor.postgres.errors.With(field).Add(1)
-			return nil, err
-		}
+// Abandon ...
+func (ps *postgresStorage) Abandon(token *mnemosyne.Token) (*mnemosyne.Session, error) {
+	query := `DELETE FROM ` + ps.table + ` WHERE token = $1 RETURNING token, subject_id, bag, expire_at`
 
-		sessions = append(sessions, &mnemosyne.Session{
-			Token:     &entity.Token,
-			SubjectId: entity.SubjectID,
-			Bag:       entity.Bag,
-			ExpireAt:  protot.TimeToTimestamp(entity.ExpireAt),
-		})
+	abandoned, err := ps.sessions("abandon", query, *token)
+	if err != nil {
+		return nil, err
 	}
-	if rows.Err() != nil {
-		ps.monitor.postgres.errors.With(field).Add(1)
-		return nil, rows.Err()
+	if len(abandoned) == 0 {
+		return nil, errSessionNotFound
 	}
 
-	return sessions, nil
+	return abandoned[0], nil
 }
 
-// Exists implements Storage interface.
-func (ps *postgresStorage) Exists(token *mnemosyne.Token) (exists bool, err error) {
-	query := `SELECT EXISTS(SELECT 1 FROM mnemosyne.session WHERE token = $1)`
-	field := metrics.Field{Key: "query", Value: query}
+// AbandonAll implements Storage interface.
+func (ps *postgresStorage) AbandonAll(subjectID string, except *mnemosyne.Token) ([]*mnemosyne.Session, error) {
+	args := []interface{}{subjectID}
+	query := `DELETE FROM ` + ps.table + ` WHERE subject_id = $1`
+	if except != nil {
+		args = append(args, *except)
+		query += ` AND token <> $2`
+	}
+	query += ` RETURNING token, subject_id, bag, expire_at`
 
-	err = ps.db.QueryRow(query, *token).Scan(
-		&exists,
+	return ps.sessions("abandon_all", query, args...)
+}
+
+// Touch implements Storage interface.
+func (ps *postgresStorage) Touch(token *mnemosyne.Token, ttl time.Duration) (*mnemosyne.Session, error) {
+	entity := &sessionEntity{
+		Token: *token,
+	}
+	query := `
+		UPDATE ` + ps.table + `
+		SET expire_at = NOW() + $2 * INTERVAL '1 microsecond'
+		WHERE token = $1 AND expire_at > NOW()
+		RETURNING subject_id, bag, expire_at
+	`
+
+	start := time.Now()
+	err := ps.db.QueryRow(query, *token, int64(ttl/time.Microsecond)).Scan(
+		&entity.SubjectID,
+		&entity.Bag,
+		&entity.ExpireAt,
 	)
+	ps.observe("touch", start, err)
 	if err != nil {
-		ps.monitor.postgres.errors.With(field).Add(1)
+		if err == sql.ErrNoRows {
+			return nil, errSessionNotFound
+		}
+		return nil, err
 	}
-	ps.monitor.postgres.queries.With(field).Add(1)
 
-	return
+	return newSessionFromSessionEntity(entity), nil
 }
 
-// Abandon ...
-func (ps *postgresStorage) Abandon(token *mnemosyne.Token) (bool, error) {
-	query := `DELETE FROM mnemosyne.session WHERE token = $1`
-	field := metrics.Field{Key: "query", Value: query}
+// SetData implements Storage interface.
+func (ps *postgresStorage) SetValue(token *mnemosyne.Token, key, value string) (*mnemosyne.Session, error) {
+	entity, err := ps.modify(token, func(entity *sessionEntity) {
+		entity.Bag.Set(key, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newSessionFromSessionEntity(entity), nil
+}
 
-	result, err := ps.db.Exec(query, *token)
+// DeleteValue implements Storage interface.
+func (ps *postgresStorage) DeleteValue(token *mnemosyne.Token, key string) (*mnemosyne.Session, error) {
+	entity, err := ps.modify(token, func(entity *sessionEntity) {
+		entity.Bag.Del(key)
+	})
 	if err != nil {
-		ps.monitor.postgres.errors.With(field).Add(1)
-		return false, err
+		return nil, err
 	}
 
-	ps.monitor.postgres.queries.With(field).Add(1)
+	return newSessionFromSessionEntity(entity), nil
+}
 
-	affected, err := result.RowsAffected()
+// Clear implements Storage interface.
+func (ps *postgresStorage) Clear(token *mnemosyne.Token) (*mnemosyne.Session, error) {
+	entity, err := ps.modify(token, func(entity *sessionEntity) {
+		entity.Bag = bagpack{}
+	})
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	if affected == 0 {
-		return false, errSessionNotFound
+	return newSessionFromSessionEntity(entity), nil
+}
+
+// Delete implements Storage interface.
+func (ps *postgresStorage) Delete(token *mnemosyne.Token, expiredAtFrom, expiredAtTo *time.Time) ([]*mnemosyne.Session, error) {
+	predicate := newPostgresPredicate().
+		token(token).
+		expireAt(expiredAtFrom, expiredAtTo)
+	if predicate.empty() {
+		return nil, errors.New("mnemosyned: session cannot be deleted, no where parameter provided")
 	}
 
-	return true, nil
+	query := "DELETE FROM " + ps.table + predicate.where() + " RETURNING token, subject_id"
+
+	return ps.removed("delete", query, predicate.args...)
 }
 
-// SetData implements Storage interface.
-func (ps *postgresStorage) SetValue(token *mnemosyne.Token, key, value string) (map[string]string, error) {
-	var err error
+// Purge implements Storage interface.
+func (ps *postgresStorage) Purge(limit int64) ([]*mnemosyne.Session, error) {
+	query := `
+		DELETE FROM ` + ps.table + `
+		WHERE token IN (
+			SELECT token
+			FROM ` + ps.table + `
+			WHERE expire_at <= NOW()
+			LIMIT $1
+		)
+		RETURNING token, subject_id
+	`
+
+	return ps.removed("purge", query, limit)
+}
+
+// Setup implements Storage interface.
+func (ps *postgresStorage) Setup() error {
+	_, err := ps.migrate()
 
+	return err
+}
+
+// TearDown implements Storage interface.
+func (ps *postgresStorage) TearDown() error {
+	_, err := ps.db.Exec(`DROP TABLE IF EXISTS ` + ps.table + `, ` + ps.versionTable)
+
+	return err
+}
+
+// modify locks session row, passes retrieved entity to the given function and persists modified bag.
+// Both operations are executed within single transaction.
+func (ps *postgresStorage) modify(token *mnemosyne.Token, fn func(*sessionEntity)) (*sessionEntity, error) {
 	entity := &sessionEntity{
 		Token: *token,
 	}
 	selectQuery := `
 		SELECT subject_id, bag, expire_at
-		FROM mnemosyne.session
-		WHERE token = $1
+		FROM ` + ps.table + `
+		WHERE token = $1 AND expire_at > NOW()
 		FOR UPDATE
 	`
 	updateQuery := `
-		UPDATE mnemosyne.session
+		UPDATE ` + ps.table + `
 		SET
-			bag = $2
+			bag = $2,
+			bag_keys = $3
 		WHERE token = $1
 	`
 
 	tx, err := ps.db.Begin()
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
+	start := time.Now()
 	err = tx.QueryRow(selectQuery, *token).Scan(
 		&entity.SubjectID,
 		&entity.Bag,
 		&entity.ExpireAt,
 	)
+	ps.observe("modify_select", start, err)
 	if err != nil {
-		ps.monitor.postgres.errors.With(metrics.Field{Key: "query", Value: selectQuery}).Add(1)
 		tx.Rollback()
 		if err == sql.ErrNoRows {
 			return nil, errSessionNotFound
 		}
 		return nil, err
 	}
-	ps.monitor.postgres.queries.With(metrics.Field{Key: "query", Value: selectQuery}).Add(1)
 
-	entity.Bag.Set(key, value)
+	fn(entity)
 
-	_, err = tx.Exec(updateQuery, *token, entity.Bag)
+	start = time.Now()
+	_, err = tx.Exec(updateQuery, *token, entity.Bag, bagpackKeys(entity.Bag))
+	ps.observe("modify_update", start, err)
 	if err != nil {
-		ps.monitor.postgres.errors.With(metrics.Field{Key: "query", Value: updateQuery}).Add(1)
 		tx.Rollback()
 		return nil, err
 	}
-	ps.monitor.postgres.queries.With(metrics.Field{Key: "query", Value: updateQuery}).Add(1)
 
-	tx.Commit()
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
 
-	return entity.Bag, nil
+	return entity, nil
 }
 
-// Delete implements Storage interface.
-func (ps *postgresStorage) Delete(token *mnemosyne.Token, expiredAtFrom, expiredAtTo *time.Time) (int64, error) {
-	if token == nil && expiredAtFrom == nil && expiredAtTo == nil {
-		return 0, errors.New("mnemosyned: session cannot be deleted, no where parameter provided")
-	}
+// Count implements Storage interface.
+func (ps *postgresStorage) Count() (count int64, err error) {
+	query := `SELECT COUNT(*) FROM ` + ps.table + ` WHERE expire_at > NOW()`
+
+	start := time.Now()
+	err = ps.db.QueryRow(query).Scan(&count)
+	ps.observe("count", start, err)
+
+	return
+}
+
+// Ping implements Storage interface.
+func (ps *postgresStorage) Ping() error {
+	start := time.Now()
+	err := ps.db.Ping()
+	ps.observe("ping", start, err)
 
-	where, args := ps.where(token, expiredAtFrom, expiredAtTo)
-	query := "DELETE FROM mnemosyne.session WHERE " + where
-	field := metrics.Field{Key: "query", Value: query}
+	return err
+}
+
+// sessions runs given query and maps every returned row into session.
+// Query is expected to return token, subject_id, bag and expire_at columns in that order.
+// Name identifies the query in metrics.
+func (ps *postgresStorage) sessions(name, query string, args ...interface{}) (sessions []*mnemosyne.Session, err error) {
+	start := time.Now()
+	defer func() {
+		ps.observe(name, start, err)
+	}()
 
-	result, err := ps.db.Exec(query, args...)
+	rows, err := ps.db.Query(query, args...)
 	if err != nil {
-		ps.monitor.postgres.errors.With(field).Add(1)
-		return 0, err
+		return nil, err
 	}
-	ps.monitor.postgres.queries.With(field).Add(1)
+	defer rows.Close()
 
-	return result.RowsAffected()
-}
+	for rows.Next() {
+		var entity sessionEntity
 
-// Setup implements Storage interface.
-func (ps *postgresStorage) Setup() error {
-	_, err := ps.db.Exec(postgresSchema)
+		err = rows.Scan(
+			&entity.Token,
+			&entity.SubjectID,
+			&entity.Bag,
+			&entity.ExpireAt,
+		)
+		if err != nil {
+			return nil, err
+		}
 
-	return err
+		sessions = append(sessions, newSessionFromSessionEntity(&entity))
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
 }
 
-// TearDown implements Storage interface.
-func (ps *postgresStorage) TearDown() error {
-	_, err := ps.db.Exec(`DROP SCHEMA mnemosyne`)
+// removed runs given delete query and maps every returned row into session that has only token and subject id set.
+// Bag can be arbitrarily large, there is no point in loading it just to drop it right away.
+func (ps *postgresStorage) removed(name, query string, args ...interface{}) (sessions []*mnemosyne.Session, err error) {
+	start := time.Now()
+	defer func() {
+		ps.observe(name, start, err)
+	}()
 
-	return err
+	rows, err := ps.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entity sessionEntity
+
+		if err = rows.Scan(&entity.Token, &entity.SubjectID); err != nil {
+			return nil, err
+		}
+
+		token := entity.Token
+		sessions = append(sessions, &mnemosyne.Session{Token: &token, SubjectId: entity.SubjectID})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
 }
 
-func (ps *postgresStorage) where(token *mnemosyne.Token, expiredAtFrom, expiredAtTo *time.Time) (string, []interface{}) {
-	switch {
-	case token != nil && expiredAtFrom == nil && expiredAtTo == nil:
-		return "token = $1", []interface{}{token}
-	case token == nil && expiredAtFrom != nil && expiredAtTo == nil:
-		return "expire_at > $1", []interface{}{expiredAtFrom}
-	case token == nil && expiredAtFrom == nil && expiredAtTo != nil:
-		return "expire_at < $1", []interface{}{expiredAtTo}
-	case token != nil && expiredAtFrom != nil && expiredAtTo == nil:
-		return "token = $1 AND expire_at > $2", []interface{}{token, expiredAtFrom}
-	case token != nil && expiredAtFrom == nil && expiredAtTo != nil:
-		return "token = $1 AND expire_at < $2", []interface{}{token, expiredAtTo}
-	case token == nil && expiredAtFrom != nil && expiredAtTo != nil:
-		return "expire_at > $1 AND expire_at < $2", []interface{}{expiredAtFrom, expiredAtTo}
-	case token != nil && expiredAtFrom != nil && expiredAtTo != nil:
-		return "token = $1 AND expire_at > $2 AND expire_at < $3", []interface{}{token, expiredAtFrom, expiredAtTo}
-	default:
-		return "", nil
+// observe records outcome and duration of the query identified by given short name.
+// Missing rows are not considered an error.
+func (ps *postgresStorage) observe(name string, start time.Time, err error) {
+	field := metrics.Field{Key: "query", Value: name}
+
+	ps.monitor.postgres.queries.With(field).Add(1)
+	ps.monitor.postgres.duration.With(field).Observe(int64(time.Since(start) / time.Microsecond))
+	if err != nil && err != sql.ErrNoRows {
+		ps.monitor.postgres.errors.With(field).Add(1)
 	}
 }
 
@@ -5,8 +5,13 @@ package main
 import (
 	"os"
 	"testing"
+	"time"
 
+	"github.com/go-kit/kit/log"
+	"github.com/piotrkowalczuk/mnemosyne"
 	"github.com/piotrkowalczuk/sklog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -22,7 +27,7 @@ func TestMain(m *testing.M) {
 	logger := initLogger(configLogger.adapter, configLogger.format, configLogger.level, sklog.KeySubsystem, "mnemosyne")
 	postgres := initPostgres(configPostgres.connectionString, logger)
 	monitor := initMonitoring(initPrometheus(config.namespace, config.subsystem, nil), logger)
-	store = initStorage(initPostgresStorage(configPostgres.tableName, postgres, monitor), logger)
+	store = initStorage(initPostgresStorage(configPostgres.schema, configPostgres.table, postgres, monitor), logger)
 
 	code := m.Run()
 
@@ -52,10 +57,101 @@ func TestPostgresStorage_Abandon(t *testing.T) {
 	testStorage_Abandon(t, store)
 }
 
+func TestPostgresStorage_AbandonAll(t *testing.T) {
+	testStorage_AbandonAll(t, store)
+}
+
+func TestPostgresStorage_Touch(t *testing.T) {
+	testStorage_Touch(t, store)
+}
+
 func TestPostgresStorage_SetValue(t *testing.T) {
 	testStorage_SetValue(t, store)
 }
 
+func TestPostgresStorage_DeleteValue(t *testing.T) {
+	testStorage_DeleteValue(t, store)
+}
+
+func TestPostgresStorage_Clear(t *testing.T) {
+	testStorage_Clear(t, store)
+}
+
 func TestPostgresStorage_Delete(t *testing.T) {
 	testStorage_Delete(t, store)
 }
+
+func TestPostgresStorage_Purge(t *testing.T) {
+	testStorage_Purge(t, store)
+}
+
+func TestPostgresStorage_Count(t *testing.T) {
+	testStorage_Count(t, store)
+}
+
+func TestPostgresStorage_migrate(t *testing.T) {
+	ps := store.(*postgresStorage)
+
+	// Setup already applied every migration.
+	applied, err := ps.migrate()
+	require.NoError(t, err)
+	assert.Equal(t, 0, applied)
+
+	states, err := ps.migrations()
+	require.NoError(t, err)
+	require.Len(t, states, len(postgresMigrations))
+	for _, state := range states {
+		assert.NotNil(t, state.appliedAt, "migration %d should be applied", state.version)
+	}
+}
+
+func TestPostgresStorage_backfillBagKeys(t *testing.T) {
+	ps := store.(*postgresStorage)
+
+	ses, err := store.Start("subjectID-backfill", map[string]string{"backfill": "value"}, ttl)
+	require.NoError(t, err)
+	defer store.Abandon(ses.Token)
+
+	// Session started before bag keys were tracked.
+	_, err = ps.db.Exec(`UPDATE `+ps.table+` SET bag_keys = '[]' WHERE token = $1`, *ses.Token)
+	require.NoError(t, err)
+
+	listed, err := store.List("", 10, "subjectID-backfill", "backfill", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, listed, 0)
+
+	tx, err := ps.db.Begin()
+	require.NoError(t, err)
+	require.NoError(t, postgresBackfillBagKeys(tx, ps.table))
+	require.NoError(t, tx.Commit())
+
+	listed, err = store.List("", 10, "subjectID-backfill", "backfill", nil, nil)
+	require.NoError(t, err)
+	if assert.Len(t, listed, 1) {
+		assert.Equal(t, ses.Token.Encode(), listed[0].Token.Encode())
+	}
+}
+
+func TestPostgresStorage_notify(t *testing.T) {
+	events := newEventBus(10)
+	ew := events.watch("subjectID-notify")
+
+	lst := newPostgresListener(config.storage.postgres.connectionString, store.(*postgresStorage).channel, events, log.NewNopLogger())
+	require.NoError(t, lst.start())
+	defer lst.stop()
+
+	ses, err := store.Start("subjectID-notify", nil, ttl)
+	require.NoError(t, err)
+	_, err = store.Abandon(ses.Token)
+	require.NoError(t, err)
+
+	for _, expected := range []mnemosyne.Event_Type{mnemosyne.Event_CREATED, mnemosyne.Event_ABANDONED} {
+		select {
+		case event := <-ew.events:
+			assert.Equal(t, expected, event.Type)
+			assert.Equal(t, ses.Token.Encode(), event.Session.Token.Encode())
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s event has not been received", expected)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/piotrkowalczuk/sklog"
+)
+
+// reaper periodically removes expired sessions from the storage.
+type reaper struct {
+	logger   log.Logger
+	storage  Storage
+	monitor  monitoringReaper
+	events   *eventBus
+	interval time.Duration
+	batch    int64
+	done     chan struct{}
+}
+
+func newReaper(logger log.Logger, storage Storage, monitor monitoringReaper, events *eventBus, interval time.Duration, batch int64) *reaper {
+	return &reaper{
+		logger:   log.NewContext(logger).With("interval", interval, "batch", batch),
+		storage:  storage,
+		monitor:  monitor,
+		events:   events,
+		interval: interval,
+		batch:    batch,
+		done:     make(chan struct{}),
+	}
+}
+
+// start runs reaper loop in separate goroutine.
+func (r *reaper) start() {
+	go r.run()
+
+	sklog.Info(r.logger, "session reaper has been started")
+}
+
+// stop terminates reaper loop.
+func (r *reaper) stop() {
+	close(r.done)
+}
+
+func (r *reaper) run() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.reap()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// reap purges expired sessions batch by batch until storage returns incomplete one.
+func (r *reaper) reap() (total int64) {
+	for {
+		purged, err := r.storage.Purge(r.batch)
+		if err != nil {
+			r.monitor.errors.Add(1)
+			sklog.Error(r.logger, err)
+
+			return
+		}
+
+		total += int64(len(purged))
+		r.monitor.purged.Add(uint64(len(purged)))
+		r.events.publish(mnemosyne.Event_EXPIRED, purged...)
+
+		if int64(len(purged)) < r.batch {
+			break
+		}
+	}
+
+	if total > 0 {
+		sklog.Debug(r.logger, "expired sessions have been purged", "purged", total)
+	}
+
+	return
+}
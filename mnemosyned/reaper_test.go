@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaper_reap(t *testing.T) {
+	storage := &storageMock{}
+	storage.On("Purge", int64(2)).Return(reaperSessions("subject-1", 2), nil).Twice()
+	storage.On("Purge", int64(2)).Return(reaperSessions("subject-2", 1), nil).Once()
+
+	events := newEventBus(10)
+	ew := events.watch("subject-2")
+
+	rpr := newReaper(log.NewNopLogger(), storage, monitoringReaper{
+		purged: discard.NewCounter("purged"),
+		errors: discard.NewCounter("errors"),
+	}, events, time.Minute, 2)
+
+	assert.Equal(t, int64(5), rpr.reap())
+	storage.AssertExpectations(t)
+
+	if assert.Len(t, ew.events, 1) {
+		event := <-ew.events
+		assert.Equal(t, mnemosyne.Event_EXPIRED, event.Type)
+		assert.Equal(t, "subject-2", event.Session.SubjectId)
+	}
+}
+
+func TestReaper_reap_error(t *testing.T) {
+	storage := &storageMock{}
+	storage.On("Purge", int64(2)).Return(reaperSessions("subject", 2), nil).Once()
+	storage.On("Purge", int64(2)).Return(nil, errors.New("fake storage error")).Once()
+
+	rpr := newReaper(log.NewNopLogger(), storage, monitoringReaper{
+		purged: discard.NewCounter("purged"),
+		errors: discard.NewCounter("errors"),
+	}, nil, time.Minute, 2)
+
+	assert.Equal(t, int64(2), rpr.reap())
+	storage.AssertExpectations(t)
+}
+
+func reaperSessions(subjectID string, n int) []*mnemosyne.Session {
+	sessions := make([]*mnemosyne.Session, 0, n)
+	for i := 0; i < n; i++ {
+		sessions = append(sessions, &mnemosyne.Session{SubjectId: subjectID})
+	}
+
+	return sessions
+}
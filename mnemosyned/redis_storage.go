@@ -0,0 +1,698 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/go-kit/kit/metrics"
+	"github.com/piotrkowalczuk/mnemosyne"
+)
+
+const (
+	redisStorageMaxRetries = 100
+)
+
+var (
+	errRedisConcurrentModification = errors.New("mnemosyned: session has been modified concurrently too many times")
+)
+
+type redisStorage struct {
+	pool      *redis.Pool
+	prefix    string
+	generator mnemosyne.RandomBytesGenerator
+	monitor   *monitoring
+}
+
+func newRedisStorage(prefix string, pool *redis.Pool, m *monitoring) Storage {
+	return &redisStorage{
+		pool:      pool,
+		prefix:    prefix,
+		generator: &mnemosyne.SystemRandomBytesGenerator{},
+		monitor:   m,
+	}
+}
+
+func initRedisStorage(prefix string, pool *redis.Pool, m *monitoring) func() (Storage, error) {
+	return func() (Storage, error) {
+		return newRedisStorage(prefix, pool, m), nil
+	}
+}
+
+// Start implements Storage interface.
+func (rs *redisStorage) Start(subjectID string, bag map[string]string, ttl time.Duration) (*mnemosyne.Session, error) {
+	token, err := mnemosyne.RandomToken(rs.generator, tmpKey)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := &sessionEntity{
+		Token:     token,
+		SubjectID: subjectID,
+		Bag:       copyBag(bag),
+		ExpireAt:  time.Now().Add(ttl),
+	}
+
+	encoded, err := entity.Bag.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	conn := rs.pool.Get()
+	defer conn.Close()
+
+	key := rs.sessionKey(&token)
+
+	conn.Send("MULTI")
+	conn.Send("HMSET", key,
+		"subject_id", entity.SubjectID,
+		"bag", encoded,
+		"expire_at", entity.ExpireAt.UnixNano(),
+	)
+	conn.Send("PEXPIREAT", key, redisMilliseconds(entity.ExpireAt))
+	conn.Send("ZADD", rs.indexKey(), redisScore(entity.ExpireAt), token.Encode())
+	conn.Send("ZADD", rs.subjectKey(entity.SubjectID), redisScore(entity.ExpireAt), token.Encode())
+	conn.Send("HSET", rs.subjectIDKey(), token.Encode(), entity.SubjectID)
+	if _, err = rs.do(conn, "EXEC"); err != nil {
+		return nil, err
+	}
+
+	return newSessionFromSessionEntity(entity), nil
+}
+
+// Get implements Storage interface.
+func (rs *redisStorage) Get(token *mnemosyne.Token) (*mnemosyne.Session, error) {
+	conn := rs.pool.Get()
+	defer conn.Close()
+
+	entity, err := rs.get(conn, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSessionFromSessionEntity(entity), nil
+}
+
+// List implements Storage interface.
+func (rs *redisStorage) List(pageToken string, limit int64, subjectID, bagKey string, expiredAtFrom, expiredAtTo *time.Time) ([]*mnemosyne.Session, error) {
+	if limit == 0 {
+		return nil, errors.New("mnemosyned: cannot retrieve list of sessions, limit needs to be higher than 0")
+	}
+
+	after, err := decodeCursor(pageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := rs.pool.Get()
+	defer conn.Close()
+
+	// Sessions of single subject are additionally indexed in separate sorted set.
+	index := rs.indexKey()
+	if subjectID != "" {
+		index = rs.subjectKey(subjectID)
+	}
+
+	sessions := make([]*mnemosyne.Session, 0, limit)
+	for int64(len(sessions)) < limit {
+		rest := limit - int64(len(sessions))
+		tokens, last, err := rs.page(conn, index, after, rest, expiredAtFrom, expiredAtTo)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, encoded := range tokens {
+			token := mnemosyne.DecodeTokenString(encoded)
+			entity, err := rs.get(conn, &token)
+			if err != nil {
+				// Session could expire between both calls.
+				if err == errSessionNotFound {
+					continue
+				}
+				return nil, err
+			}
+			// Bag is not indexed, so sessions are filtered once retrieved.
+			if bagKey != "" && !entity.Bag.Has(bagKey) {
+				continue
+			}
+
+			sessions = append(sessions, newSessionFromSessionEntity(entity))
+		}
+
+		if int64(len(tokens)) < rest {
+			break
+		}
+		after = last
+	}
+
+	return sessions, nil
+}
+
+// page returns up to limit tokens from the index that are placed after the cursor,
+// together with the cursor that points at the last of them.
+func (rs *redisStorage) page(conn redis.Conn, index string, after *cursor, limit int64, expiredAtFrom, expiredAtTo *time.Time) ([]string, *cursor, error) {
+	var (
+		tokens []string
+		last   *cursor
+	)
+	min, max := redisRange(expiredAtFrom, expiredAtTo)
+	if after != nil {
+		score := redisScore(after.expireAt)
+
+		// Members that share score with the cursor are ordered lexicographically,
+		// the same way as tokens are compared by the cursor.
+		if redisWithin(score, expiredAtFrom, expiredAtTo) {
+			ties, err := redis.Strings(rs.do(conn, "ZRANGEBYSCORE", index, score, score))
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, encoded := range ties {
+				if encoded > after.token.Encode() && int64(len(tokens)) < limit {
+					tokens = append(tokens, encoded)
+					last = &cursor{expireAt: after.expireAt, token: mnemosyne.DecodeTokenString(encoded)}
+				}
+			}
+		}
+		if expiredAtFrom == nil || score > redisScore(*expiredAtFrom) {
+			min = "(" + strconv.FormatInt(score, 10)
+		}
+	}
+	if rest := limit - int64(len(tokens)); rest > 0 {
+		members, err := redis.Strings(rs.do(conn, "ZRANGEBYSCORE", index, min, max, "WITHSCORES", "LIMIT", 0, rest))
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := 0; i+1 < len(members); i += 2 {
+			score, err := strconv.ParseFloat(members[i+1], 64)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			tokens = append(tokens, members[i])
+			last = &cursor{
+				expireAt: time.Unix(0, int64(score)*int64(time.Microsecond)),
+				token:    mnemosyne.DecodeTokenString(members[i]),
+			}
+		}
+	}
+
+	return tokens, last, nil
+}
+
+// Exists implements Storage interface.
+func (rs *redisStorage) Exists(token *mnemosyne.Token) (bool, error) {
+	conn := rs.pool.Get()
+	defer conn.Close()
+
+	if _, err := rs.get(conn, token); err != nil {
+		if err == errSessionNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Abandon implements Storage interface.
+func (rs *redisStorage) Abandon(token *mnemosyne.Token) (*mnemosyne.Session, error) {
+	conn := rs.pool.Get()
+	defer conn.Close()
+
+	abandoned, _, err := rs.delete(conn, token.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(abandoned) == 0 {
+		return nil, errSessionNotFound
+	}
+
+	return abandoned[0], nil
+}
+
+// AbandonAll implements Storage interface.
+func (rs *redisStorage) AbandonAll(subjectID string, except *mnemosyne.Token) ([]*mnemosyne.Session, error) {
+	conn := rs.pool.Get()
+	defer conn.Close()
+
+	members, err := redis.Strings(rs.do(conn, "ZRANGE", rs.subjectKey(subjectID), 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]string, 0, len(members))
+	for _, encoded := range members {
+		if except != nil && encoded == except.Encode() {
+			continue
+		}
+		tokens = append(tokens, encoded)
+	}
+
+	deleted, _, err := rs.delete(conn, tokens...)
+
+	return deleted, err
+}
+
+// Touch implements Storage interface.
+func (rs *redisStorage) Touch(token *mnemosyne.Token, ttl time.Duration) (*mnemosyne.Session, error) {
+	entity, err := rs.modify(token, func(conn redis.Conn, entity *sessionEntity) error {
+		entity.ExpireAt = time.Now().Add(ttl)
+
+		key := rs.sessionKey(token)
+		conn.Send("HSET", key, "expire_at", entity.ExpireAt.UnixNano())
+		conn.Send("PEXPIREAT", key, redisMilliseconds(entity.ExpireAt))
+		conn.Send("ZADD", rs.indexKey(), redisScore(entity.ExpireAt), token.Encode())
+		conn.Send("ZADD", rs.subjectKey(entity.SubjectID), redisScore(entity.ExpireAt), token.Encode())
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newSessionFromSessionEntity(entity), nil
+}
+
+// SetValue implements Storage interface.
+func (rs *redisStorage) SetValue(token *mnemosyne.Token, key, value string) (*mnemosyne.Session, error) {
+	entity, err := rs.modify(token, func(conn redis.Conn, entity *sessionEntity) error {
+		entity.Bag.Set(key, value)
+
+		return rs.saveBag(conn, entity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newSessionFromSessionEntity(entity), nil
+}
+
+// DeleteValue implements Storage interface.
+func (rs *redisStorage) DeleteValue(token *mnemosyne.Token, key string) (*mnemosyne.Session, error) {
+	entity, err := rs.modify(token, func(conn redis.Conn, entity *sessionEntity) error {
+		entity.Bag.Del(key)
+
+		return rs.saveBag(conn, entity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newSessionFromSessionEntity(entity), nil
+}
+
+// Clear implements Storage interface.
+func (rs *redisStorage) Clear(token *mnemosyne.Token) (*mnemosyne.Session, error) {
+	entity, err := rs.modify(token, func(conn redis.Conn, entity *sessionEntity) error {
+		entity.Bag = bagpack{}
+
+		return rs.saveBag(conn, entity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newSessionFromSessionEntity(entity), nil
+}
+
+// Delete implements Storage interface.
+func (rs *redisStorage) Delete(token *mnemosyne.Token, expiredAtFrom, expiredAtTo *time.Time) ([]*mnemosyne.Session, error) {
+	if token == nil && expiredAtFrom == nil && expiredAtTo == nil {
+		return nil, errors.New("mnemosyned: session cannot be deleted, no where parameter provided")
+	}
+
+	conn := rs.pool.Get()
+	defer conn.Close()
+
+	if token != nil {
+		score, err := redis.Float64(rs.do(conn, "ZSCORE", rs.indexKey(), token.Encode()))
+		if err != nil {
+			if err == redis.ErrNil {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if !redisWithin(int64(score), expiredAtFrom, expiredAtTo) {
+			return nil, nil
+		}
+
+		deleted, _, err := rs.delete(conn, token.Encode())
+
+		return deleted, err
+	}
+
+	min, max := redisRange(expiredAtFrom, expiredAtTo)
+	tokens, err := redis.Strings(rs.do(conn, "ZRANGEBYSCORE", rs.indexKey(), min, max))
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, _, err := rs.delete(conn, tokens...)
+
+	return deleted, err
+}
+
+// Purge implements Storage interface.
+// Session keys are evicted by redis itself, what is left to remove are their index entries.
+func (rs *redisStorage) Purge(limit int64) ([]*mnemosyne.Session, error) {
+	conn := rs.pool.Get()
+	defer conn.Close()
+
+	max := "(" + strconv.FormatInt(redisScore(time.Now()), 10)
+	tokens, err := redis.Strings(rs.do(conn, "ZRANGEBYSCORE", rs.indexKey(), "-inf", max, "LIMIT", 0, limit))
+	if err != nil {
+		return nil, err
+	}
+	purged, evicted, err := rs.delete(conn, tokens...)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(purged, evicted...), nil
+}
+
+// Count implements Storage interface.
+// Index can still contain entries of sessions already evicted by redis, expired ones are not counted.
+func (rs *redisStorage) Count() (int64, error) {
+	conn := rs.pool.Get()
+	defer conn.Close()
+
+	min := "(" + strconv.FormatInt(redisScore(time.Now()), 10)
+
+	return redis.Int64(rs.do(conn, "ZCOUNT", rs.indexKey(), min, "+inf"))
+}
+
+// Ping implements Storage interface.
+func (rs *redisStorage) Ping() error {
+	conn := rs.pool.Get()
+	defer conn.Close()
+
+	_, err := rs.do(conn, "PING")
+
+	return err
+}
+
+// Setup implements Storage interface.
+func (rs *redisStorage) Setup() error {
+	return rs.Ping()
+}
+
+// TearDown implements Storage interface.
+func (rs *redisStorage) TearDown() error {
+	conn := rs.pool.Get()
+	defer conn.Close()
+
+	keys, err := redis.Values(rs.do(conn, "KEYS", rs.prefix+":*"))
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err = rs.do(conn, "DEL", keys...)
+
+	return err
+}
+
+func (rs *redisStorage) get(conn redis.Conn, token *mnemosyne.Token) (*sessionEntity, error) {
+	values, err := redis.Values(rs.do(conn, "HMGET", rs.sessionKey(token), "subject_id", "bag", "expire_at"))
+	if err != nil {
+		return nil, err
+	}
+	entity, err := newRedisSessionEntity(token, values)
+	if err != nil {
+		return nil, err
+	}
+	// Key expiration has millisecond precision, session can be outdated even if key still exists.
+	if !entity.ExpireAt.After(time.Now()) {
+		return nil, errSessionNotFound
+	}
+
+	return entity, nil
+}
+
+// modify retrieves session and passes it to the given function that queues write commands inside MULTI block.
+// Whole operation is guarded by WATCH and retried if session has been modified concurrently.
+func (rs *redisStorage) modify(token *mnemosyne.Token, fn func(redis.Conn, *sessionEntity) error) (*sessionEntity, error) {
+	conn := rs.pool.Get()
+	defer conn.Close()
+
+	for i := 0; i < redisStorageMaxRetries; i++ {
+		if _, err := rs.do(conn, "WATCH", rs.sessionKey(token)); err != nil {
+			return nil, err
+		}
+
+		entity, err := rs.get(conn, token)
+		if err != nil {
+			rs.do(conn, "UNWATCH")
+			return nil, err
+		}
+
+		conn.Send("MULTI")
+		if err = fn(conn, entity); err != nil {
+			rs.do(conn, "DISCARD")
+			return nil, err
+		}
+
+		replies, err := redis.Values(rs.do(conn, "EXEC"))
+		// No replies means that watched key has been modified in the meantime.
+		if err == redis.ErrNil || (err == nil && len(replies) == 0) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return entity, nil
+	}
+
+	return nil, errRedisConcurrentModification
+}
+
+func (rs *redisStorage) saveBag(conn redis.Conn, entity *sessionEntity) error {
+	encoded, err := entity.Bag.Value()
+	if err != nil {
+		return err
+	}
+
+	return conn.Send("HSET", rs.sessionKey(&entity.Token), "bag", encoded)
+}
+
+// delete removes sessions identified by given tokens and returns those of them that still existed.
+// Sessions already evicted by redis itself are returned separately, they are known only by token and subject id.
+func (rs *redisStorage) delete(conn redis.Conn, tokens ...string) ([]*mnemosyne.Session, []*mnemosyne.Session, error) {
+	if len(tokens) == 0 {
+		return nil, nil, nil
+	}
+
+	keys := make([]interface{}, 0, len(tokens))
+	members := make([]interface{}, 0, len(tokens)+1)
+	members = append(members, rs.indexKey())
+	fields := make([]interface{}, 0, len(tokens)+1)
+	fields = append(fields, rs.subjectIDKey())
+	for _, encoded := range tokens {
+		token := mnemosyne.DecodeTokenString(encoded)
+		keys = append(keys, rs.sessionKey(&token))
+		members = append(members, encoded)
+		fields = append(fields, encoded)
+	}
+
+	for _, key := range keys {
+		conn.Send("HMGET", key, "subject_id", "bag", "expire_at")
+	}
+	// Subject of already evicted session is known only thanks to the subject id index.
+	conn.Send("HMGET", fields...)
+	if err := conn.Flush(); err != nil {
+		return nil, nil, err
+	}
+	var (
+		err      error
+		deleted  []*mnemosyne.Session
+		evicted  []*mnemosyne.Session
+		subjects = make(map[string][]interface{})
+	)
+	subject := func(subjectID, encoded string) {
+		if _, ok := subjects[subjectID]; !ok {
+			subjects[subjectID] = []interface{}{rs.subjectKey(subjectID)}
+		}
+		subjects[subjectID] = append(subjects[subjectID], encoded)
+	}
+	// Every reply needs to be received, even if one of them is an error.
+	found := make(map[string]bool, len(tokens))
+	for _, encoded := range tokens {
+		values, rerr := redis.Values(conn.Receive())
+		if rerr != nil {
+			err = rerr
+			continue
+		}
+
+		token := mnemosyne.DecodeTokenString(encoded)
+		entity, rerr := newRedisSessionEntity(&token, values)
+		switch {
+		case rerr == errSessionNotFound:
+		case rerr != nil:
+			err = rerr
+		default:
+			found[encoded] = true
+			subject(entity.SubjectID, encoded)
+			deleted = append(deleted, newSessionFromSessionEntity(entity))
+		}
+	}
+	subjectIDs, rerr := redis.Values(conn.Receive())
+	if rerr != nil {
+		err = rerr
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, encoded := range tokens {
+		if found[encoded] {
+			continue
+		}
+		token := mnemosyne.DecodeTokenString(encoded)
+		ses := &mnemosyne.Session{Token: &token}
+		if subjectIDs[i] != nil {
+			if ses.SubjectId, err = redis.String(subjectIDs[i], nil); err != nil {
+				return nil, nil, err
+			}
+			subject(ses.SubjectId, encoded)
+		}
+		evicted = append(evicted, ses)
+	}
+
+	conn.Send("MULTI")
+	conn.Send("DEL", keys...)
+	conn.Send("ZREM", members...)
+	conn.Send("HDEL", fields...)
+	for _, subjectMembers := range subjects {
+		conn.Send("ZREM", subjectMembers...)
+	}
+	if _, err = rs.do(conn, "EXEC"); err != nil {
+		return nil, nil, err
+	}
+
+	return deleted, evicted, nil
+}
+
+func (rs *redisStorage) do(conn redis.Conn, command string, args ...interface{}) (interface{}, error) {
+	field := metrics.Field{Key: "command", Value: command}
+
+	start := time.Now()
+	reply, err := conn.Do(command, args...)
+	rs.monitor.redis.duration.With(field).Observe(int64(time.Since(start) / time.Microsecond))
+	if err != nil {
+		rs.monitor.redis.errors.With(field).Add(1)
+		return nil, err
+	}
+	rs.monitor.redis.commands.With(field).Add(1)
+
+	return reply, nil
+}
+
+func (rs *redisStorage) sessionKey(token *mnemosyne.Token) string {
+	return rs.prefix + ":session:" + token.Encode()
+}
+
+func (rs *redisStorage) indexKey() string {
+	return rs.prefix + ":expire_at"
+}
+
+// subjectIDKey is the key of a hash that maps tokens to subject ids,
+// it outlives evicted sessions, so their entries in subject index can be removed.
+func (rs *redisStorage) subjectIDKey() string {
+	return rs.prefix + ":subject_id"
+}
+
+func (rs *redisStorage) subjectKey(subjectID string) string {
+	return rs.prefix + ":subject:" + subjectID
+}
+
+// redisScore converts time into sorted set score with microsecond precision,
+// which is the highest one that still fits into float64 without loss.
+func redisScore(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Microsecond)
+}
+
+func redisMilliseconds(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func redisRange(expiredAtFrom, expiredAtTo *time.Time) (interface{}, interface{}) {
+	var min, max interface{} = "-inf", "+inf"
+	if expiredAtFrom != nil {
+		min = "(" + strconv.FormatInt(redisScore(*expiredAtFrom), 10)
+	}
+	if expiredAtTo != nil {
+		max = "(" + strconv.FormatInt(redisScore(*expiredAtTo), 10)
+	}
+
+	return min, max
+}
+
+func redisWithin(score int64, expiredAtFrom, expiredAtTo *time.Time) bool {
+	if expiredAtFrom != nil && score <= redisScore(*expiredAtFrom) {
+		return false
+	}
+	if expiredAtTo != nil && score >= redisScore(*expiredAtTo) {
+		return false
+	}
+
+	return true
+}
+
+// newRedisSessionEntity maps reply of HMGET subject_id, bag, expire_at command into session entity.
+func newRedisSessionEntity(token *mnemosyne.Token, values []interface{}) (*sessionEntity, error) {
+	if values[0] == nil {
+		return nil, errSessionNotFound
+	}
+
+	var err error
+	entity := &sessionEntity{
+		Token: *token,
+	}
+	if entity.SubjectID, err = redis.String(values[0], nil); err != nil {
+		return nil, err
+	}
+	if err = entity.Bag.Scan(values[1]); err != nil {
+		return nil, err
+	}
+	expireAt, err := redis.Int64(values[2], nil)
+	if err != nil {
+		return nil, err
+	}
+	entity.ExpireAt = time.Unix(0, expireAt)
+
+	return entity, nil
+}
+
+func newRedisPool(address, password string, database int) *redis.Pool {
+	return &redis.Pool{
+		MaxIdle:     10,
+		IdleTimeout: 240 * time.Second,
+		Dial: func() (redis.Conn, error) {
+			conn, err := redis.Dial("tcp", address)
+			if err != nil {
+				return nil, err
+			}
+			if password != "" {
+				if _, err := conn.Do("AUTH", password); err != nil {
+					conn.Close()
+					return nil, err
+				}
+			}
+			if _, err := conn.Do("SELECT", database); err != nil {
+				conn.Close()
+				return nil, err
+			}
+
+			return conn, nil
+		},
+		TestOnBorrow: func(conn redis.Conn, t time.Time) error {
+			_, err := conn.Do("PING")
+			return err
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-kit/kit/log"
+	"github.com/piotrkowalczuk/mnemosyne"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	redisServer *miniredis.Miniredis
+	redisStore  Storage
+)
+
+func init() {
+	var err error
+
+	redisServer, err = miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+
+	logger := log.NewNopLogger()
+	monitor := initMonitoring(initPrometheus("mnemosyne_test", "mnemosyne", stdprometheus.Labels{"server": "redis_test"}), logger)
+	redisStore = initStorage(initRedisStorage("mnemosyne_test", initRedis(redisServer.Addr(), "", 0, logger), monitor), logger)
+}
+
+func TestRedisStorage_Start(t *testing.T) {
+	testStorage_Start(t, redisStore)
+}
+
+func TestRedisStorage_Get(t *testing.T) {
+	testStorage_Get(t, redisStore)
+}
+
+func TestRedisStorage_List(t *testing.T) {
+	testStorage_List(t, redisStore)
+}
+
+func TestRedisStorage_Exists(t *testing.T) {
+	testStorage_Exists(t, redisStore)
+}
+
+func TestRedisStorage_Abandon(t *testing.T) {
+	testStorage_Abandon(t, redisStore)
+}
+
+func TestRedisStorage_AbandonAll(t *testing.T) {
+	testStorage_AbandonAll(t, redisStore)
+}
+
+func TestRedisStorage_Touch(t *testing.T) {
+	testStorage_Touch(t, redisStore)
+}
+
+func TestRedisStorage_SetValue(t *testing.T) {
+	testStorage_SetValue(t, redisStore)
+}
+
+func TestRedisStorage_DeleteValue(t *testing.T) {
+	testStorage_DeleteValue(t, redisStore)
+}
+
+func TestRedisStorage_Clear(t *testing.T) {
+	testStorage_Clear(t, redisStore)
+}
+
+func TestRedisStorage_Delete(t *testing.T) {
+	testStorage_Delete(t, redisStore)
+}
+
+func TestRedisStorage_Purge(t *testing.T) {
+	testStorage_Purge(t, redisStore)
+}
+
+func TestRedisStorage_Count(t *testing.T) {
+	testStorage_Count(t, redisStore)
+}
+
+func TestRedisStorage_Purge_evicted(t *testing.T) {
+	rs := redisStore.(*redisStorage)
+
+	ses, err := rs.Start("evicted-subject", nil, -time.Minute)
+	require.NoError(t, err)
+
+	// Redis evicts expired session on its own, only index entries are left behind.
+	redisServer.Del(rs.sessionKey(ses.Token))
+
+	// Listing must not drop index entries that purge relies on.
+	_, err = rs.List("", 10, "evicted-subject", "", nil, nil)
+	require.NoError(t, err)
+	_, err = rs.List("", 10, "", "", nil, nil)
+	require.NoError(t, err)
+
+	var evicted *mnemosyne.Session
+	for {
+		purged, err := rs.Purge(100)
+		require.NoError(t, err)
+		for _, p := range purged {
+			if p.Token.Encode() == ses.Token.Encode() {
+				evicted = p
+			}
+		}
+		if len(purged) < 100 {
+			break
+		}
+	}
+
+	if assert.NotNil(t, evicted, "evicted session should be purged") {
+		assert.Equal(t, "evicted-subject", evicted.SubjectId)
+	}
+
+	assert.False(t, redisServer.Exists(rs.subjectKey("evicted-subject")), "subject index should be removed")
+	fields, err := redisServer.HKeys(rs.subjectIDKey())
+	if err == nil {
+		assert.NotContains(t, fields, ses.Token.Encode())
+	}
+}
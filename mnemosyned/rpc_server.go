@@ -1,6 +1,8 @@
 package main
 
 import (
+	"time"
+
 	"github.com/go-kit/kit/log"
 	"github.com/piotrkowalczuk/mnemosyne"
 	"github.com/piotrkowalczuk/sklog"
@@ -13,29 +15,36 @@ type rpcServer struct {
 	logger  log.Logger
 	monitor *monitoring
 	storage Storage
+	opts    handlerOpts
 	alloc   struct {
-		abandon  handlerFunc
-		context  handlerFunc
-		delete   handlerFunc
-		exists   handlerFunc
-		get      handlerFunc
-		list     handlerFunc
-		setValue handlerFunc
-		start    handlerFunc
+		abandon     handlerFunc
+		abandonAll  handlerFunc
+		clear       handlerFunc
+		context     handlerFunc
+		delete      handlerFunc
+		deleteValue handlerFunc
+		exists      handlerFunc
+		get         handlerFunc
+		list        handlerFunc
+		setValue    handlerFunc
+		start       handlerFunc
+		touch       handlerFunc
+		watch       handlerFunc
 	}
 }
 
 // Get implements mnemosyne.RPCServer interface.
 func (rs *rpcServer) Context(ctx context.Context, req *mnemosyne.Empty) (*mnemosyne.Session, error) {
-	h := rs.alloc.context(rs.logger, rs.storage, rs.monitor.rpc)
+	h := rs.alloc.context(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
 	h.monitor.requests.Add(1)
+	defer h.measure(time.Now())
 
 	ses, err := h.context(ctx)
 	if err != nil {
-		h.monitor.errors.Add(1)
-		sklog.Error(h.logger, err)
+		err = rs.error(err)
+		h.failed(err)
 
-		return nil, rs.error(err)
+		return nil, err
 	}
 
 	sklog.Debug(h.logger, "session has been retrieved (by context)")
@@ -45,15 +54,16 @@ func (rs *rpcServer) Context(ctx context.Context, req *mnemosyne.Empty) (*mnemos
 
 // Get implements mnemosyne.RPCServer interface.
 func (rs *rpcServer) Get(ctx context.Context, req *mnemosyne.GetRequest) (*mnemosyne.GetResponse, error) {
-	h := rs.alloc.get(rs.logger, rs.storage, rs.monitor.rpc)
+	h := rs.alloc.get(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
 	h.monitor.requests.Add(1)
+	defer h.measure(time.Now())
 
 	ses, err := h.get(ctx, req)
 	if err != nil {
-		h.monitor.errors.Add(1)
-		sklog.Error(h.logger, err)
+		err = rs.error(err)
+		h.failed(err)
 
-		return nil, rs.error(err)
+		return nil, err
 	}
 
 	sklog.Debug(h.logger, "session has been retrieved (by token)")
@@ -65,35 +75,38 @@ func (rs *rpcServer) Get(ctx context.Context, req *mnemosyne.GetRequest) (*mnemo
 
 // List implements mnemosyne.RPCServer interface.
 func (rs *rpcServer) List(ctx context.Context, req *mnemosyne.ListRequest) (*mnemosyne.ListResponse, error) {
-	h := rs.alloc.list(rs.logger, rs.storage, rs.monitor.rpc)
+	h := rs.alloc.list(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
 	h.monitor.requests.Add(1)
+	defer h.measure(time.Now())
 
-	sessions, err := h.list(ctx, req)
+	sessions, next, err := h.list(ctx, req)
 	if err != nil {
-		h.monitor.errors.Add(1)
-		sklog.Error(h.logger, err)
+		err = rs.error(err)
+		h.failed(err)
 
-		return nil, rs.error(err)
+		return nil, err
 	}
 
 	sklog.Debug(h.logger, "session list has been retrieved")
 
 	return &mnemosyne.ListResponse{
-		Sessions: sessions,
+		Sessions:      sessions,
+		NextPageToken: next,
 	}, nil
 }
 
 // Start implements mnemosyne.RPCServer interface.
 func (rs *rpcServer) Start(ctx context.Context, req *mnemosyne.StartRequest) (*mnemosyne.StartResponse, error) {
-	h := rs.alloc.start(rs.logger, rs.storage, rs.monitor.rpc)
+	h := rs.alloc.start(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
 	h.monitor.requests.Add(1)
+	defer h.measure(time.Now())
 
 	ses, err := h.start(ctx, req)
 	if err != nil {
-		h.monitor.errors.Add(1)
-		sklog.Error(h.logger, err)
+		err = rs.error(err)
+		h.failed(err)
 
-		return nil, rs.error(err)
+		return nil, err
 	}
 
 	sklog.Debug(h.logger, "session has been started")
@@ -105,15 +118,16 @@ func (rs *rpcServer) Start(ctx context.Context, req *mnemosyne.StartRequest) (*m
 
 // Exists implements mnemosyne.RPCServer interface.
 func (rs *rpcServer) Exists(ctx context.Context, req *mnemosyne.ExistsRequest) (*mnemosyne.ExistsResponse, error) {
-	h := rs.alloc.exists(rs.logger, rs.storage, rs.monitor.rpc)
+	h := rs.alloc.exists(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
 	h.monitor.requests.Add(1)
+	defer h.measure(time.Now())
 
 	exists, err := h.exists(ctx, req)
 	if err != nil {
-		h.monitor.errors.Add(1)
-		sklog.Error(h.logger, err)
+		err = rs.error(err)
+		h.failed(err)
 
-		return nil, rs.error(err)
+		return nil, err
 	}
 
 	sklog.Debug(h.logger, "session presence has been checked")
@@ -125,15 +139,16 @@ func (rs *rpcServer) Exists(ctx context.Context, req *mnemosyne.ExistsRequest) (
 
 // Abandon implements mnemosyne.RPCServer interface.
 func (rs *rpcServer) Abandon(ctx context.Context, req *mnemosyne.AbandonRequest) (*mnemosyne.AbandonResponse, error) {
-	h := rs.alloc.abandon(rs.logger, rs.storage, rs.monitor.rpc)
+	h := rs.alloc.abandon(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
 	h.monitor.requests.Add(1)
+	defer h.measure(time.Now())
 
 	abandoned, err := h.abandon(ctx, req)
 	if err != nil {
-		h.monitor.errors.Add(1)
-		sklog.Error(h.logger, err)
+		err = rs.error(err)
+		h.failed(err)
 
-		return nil, rs.error(err)
+		return nil, err
 	}
 
 	sklog.Debug(h.logger, "session has been abandoned")
@@ -143,17 +158,60 @@ func (rs *rpcServer) Abandon(ctx context.Context, req *mnemosyne.AbandonRequest)
 	}, nil
 }
 
+// AbandonAll implements mnemosyne.RPCServer interface.
+func (rs *rpcServer) AbandonAll(ctx context.Context, req *mnemosyne.AbandonAllRequest) (*mnemosyne.AbandonAllResponse, error) {
+	h := rs.alloc.abandonAll(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
+	h.monitor.requests.Add(1)
+	defer h.measure(time.Now())
+
+	affected, err := h.abandonAll(ctx, req)
+	if err != nil {
+		err = rs.error(err)
+		h.failed(err)
+
+		return nil, err
+	}
+
+	sklog.Debug(h.logger, "sessions of subject have been abandoned")
+
+	return &mnemosyne.AbandonAllResponse{
+		Count: affected,
+	}, nil
+}
+
+// Touch implements mnemosyne.RPCServer interface.
+func (rs *rpcServer) Touch(ctx context.Context, req *mnemosyne.TouchRequest) (*mnemosyne.TouchResponse, error) {
+	h := rs.alloc.touch(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
+	h.monitor.requests.Add(1)
+	defer h.measure(time.Now())
+
+	ses, err := h.touch(ctx, req)
+	if err != nil {
+		err = rs.error(err)
+		h.failed(err)
+
+		return nil, err
+	}
+
+	sklog.Debug(h.logger, "session has been touched")
+
+	return &mnemosyne.TouchResponse{
+		Session: ses,
+	}, nil
+}
+
 // SetValue implements mnemosyne.RPCServer interface.
 func (rs *rpcServer) SetValue(ctx context.Context, req *mnemosyne.SetValueRequest) (*mnemosyne.SetValueResponse, error) {
-	h := rs.alloc.setValue(rs.logger, rs.storage, rs.monitor.rpc)
+	h := rs.alloc.setValue(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
 	h.monitor.requests.Add(1)
+	defer h.measure(time.Now())
 
 	bag, err := h.setValue(ctx, req)
 	if err != nil {
-		h.monitor.errors.Add(1)
-		sklog.Error(h.logger, err)
+		err = rs.error(err)
+		h.failed(err)
 
-		return nil, rs.error(err)
+		return nil, err
 	}
 
 	sklog.Debug(h.logger, "session bag value has been set")
@@ -163,17 +221,57 @@ func (rs *rpcServer) SetValue(ctx context.Context, req *mnemosyne.SetValueReques
 	}, nil
 }
 
+// DeleteValue implements mnemosyne.RPCServer interface.
+func (rs *rpcServer) DeleteValue(ctx context.Context, req *mnemosyne.DeleteValueRequest) (*mnemosyne.DeleteValueResponse, error) {
+	h := rs.alloc.deleteValue(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
+	h.monitor.requests.Add(1)
+	defer h.measure(time.Now())
+
+	ses, err := h.deleteValue(ctx, req)
+	if err != nil {
+		err = rs.error(err)
+		h.failed(err)
+
+		return nil, err
+	}
+
+	sklog.Debug(h.logger, "session bag value has been deleted")
+
+	return &mnemosyne.DeleteValueResponse{
+		Session: ses,
+	}, nil
+}
+
+// Clear implements mnemosyne.RPCServer interface.
+func (rs *rpcServer) Clear(ctx context.Context, req *mnemosyne.ClearRequest) (*mnemosyne.ClearResponse, error) {
+	h := rs.alloc.clear(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
+	h.monitor.requests.Add(1)
+	defer h.measure(time.Now())
+
+	if _, err := h.clear(ctx, req); err != nil {
+		err = rs.error(err)
+		h.failed(err)
+
+		return nil, err
+	}
+
+	sklog.Debug(h.logger, "session bag has been cleared")
+
+	return &mnemosyne.ClearResponse{}, nil
+}
+
 // Delete implements mnemosyne.RPCServer interface.
 func (rs *rpcServer) Delete(ctx context.Context, req *mnemosyne.DeleteRequest) (*mnemosyne.DeleteResponse, error) {
-	h := rs.alloc.delete(rs.logger, rs.storage, rs.monitor.rpc)
+	h := rs.alloc.delete(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
 	h.monitor.requests.Add(1)
+	defer h.measure(time.Now())
 
 	affected, err := h.delete(ctx, req)
 	if err != nil {
-		h.monitor.errors.Add(1)
-		sklog.Error(h.logger, err)
+		err = rs.error(err)
+		h.failed(err)
 
-		return nil, rs.error(err)
+		return nil, err
 	}
 
 	sklog.Debug(h.logger, "session value has been deleted")
@@ -183,6 +281,45 @@ func (rs *rpcServer) Delete(ctx context.Context, req *mnemosyne.DeleteRequest) (
 	}, nil
 }
 
+// Watch implements mnemosyne.RPCServer interface.
+func (rs *rpcServer) Watch(req *mnemosyne.WatchRequest, stream mnemosyne.RPC_WatchServer) error {
+	h := rs.alloc.watch(rs.logger, rs.storage, rs.monitor.rpc, rs.opts)
+	h.monitor.requests.Add(1)
+
+	ew, err := h.watch(stream.Context(), req)
+	if err != nil {
+		err = rs.error(err)
+		h.failed(err)
+
+		return err
+	}
+	defer h.opts.events.unwatch(ew)
+
+	sklog.Debug(h.logger, "session events are being watched")
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			sklog.Debug(h.logger, "session events watch has been finished")
+
+			return nil
+		case event, ok := <-ew.events:
+			if !ok {
+				err := rs.error(ew.err)
+				h.failed(err)
+
+				return err
+			}
+
+			if err := stream.Send(event); err != nil {
+				h.failed(err)
+
+				return err
+			}
+		}
+	}
+}
+
 func (rs *rpcServer) error(err error) error {
 	if err == nil {
 		return nil
@@ -191,7 +328,16 @@ func (rs *rpcServer) error(err error) error {
 	switch err {
 	case errSessionNotFound:
 		return mnemosyne.ErrSessionNotFound
-	default:
-		return grpc.Errorf(codes.Internal, err.Error())
+	case errWatcherTooSlow:
+		return grpc.Errorf(codes.ResourceExhausted, err.Error())
+	case errEventBusClosed:
+		return grpc.Errorf(codes.Unavailable, err.Error())
 	}
+
+	// Errors that already carry status code (e.g. validation errors) are returned as is.
+	if grpc.Code(err) != codes.Unknown {
+		return err
+	}
+
+	return grpc.Errorf(codes.Internal, err.Error())
 }
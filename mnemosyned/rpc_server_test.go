@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"time"
 
 	"github.com/lib/pq"
 	. "github.com/onsi/ginkgo"
@@ -73,7 +74,7 @@ var _ = Describe("RPCServer", func() {
 			})
 			Context("without storage error", func() {
 				BeforeEach(func() {
-					storage.On("Start", mock.AnythingOfType("string"), mock.AnythingOfType("map[string]string")).
+					storage.On("Start", mock.AnythingOfType("string"), mock.AnythingOfType("map[string]string"), ttl).
 						Return(session, expectedErr).
 						Once()
 				})
@@ -82,7 +83,7 @@ var _ = Describe("RPCServer", func() {
 			Context("with storage postgres error", func() {
 				BeforeEach(func() {
 					expectedErr = pq.Error{Message: "fake postgres error"}
-					storage.On("Start", mock.AnythingOfType("string"), mock.AnythingOfType("map[string]string")).
+					storage.On("Start", mock.AnythingOfType("string"), mock.AnythingOfType("map[string]string"), ttl).
 						Return(nil, expectedErr).
 						Once()
 				})
@@ -98,17 +99,38 @@ var _ = Describe("RPCServer", func() {
 			BeforeEach(func() {
 				req = &mnemosyne.StartRequest{SubjectId: subjectID}
 				session = &mnemosyne.Session{Token: token, SubjectId: subjectID, ExpireAt: protot.Now()}
-				storage.On("Start", mock.AnythingOfType("string"), mock.AnythingOfType("map[string]string")).
+				storage.On("Start", mock.AnythingOfType("string"), mock.AnythingOfType("map[string]string"), ttl).
 					Return(session, expectedErr).
 					Once()
 			})
 			itSuccess()
 		})
+		Context("with subject and custom ttl", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.StartRequest{SubjectId: subjectID, Bag: bag, Ttl: 60}
+				session = &mnemosyne.Session{Token: token, SubjectId: subjectID, Bag: bag, ExpireAt: protot.Now()}
+				storage.On("Start", mock.AnythingOfType("string"), mock.AnythingOfType("map[string]string"), time.Minute).
+					Return(session, expectedErr).
+					Once()
+			})
+			itSuccess()
+		})
+		Context("with subject and ttl longer than maximum", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.StartRequest{SubjectId: subjectID, Bag: bag, Ttl: int64(ttlMax/time.Second) + 1}
+			})
+			It("should return grpc error with code 3", func() {
+				AssertGRPCError(err, codes.InvalidArgument, "mnemosyne: session ttl cannot be longer than "+ttlMax.String())
+			})
+			It("should return an nil response", func() {
+				Expect(res).To(BeNil())
+			})
+		})
 		Context("without subject and with bag", func() {
 			BeforeEach(func() {
 				req = &mnemosyne.StartRequest{Bag: bag}
 				expectedErr = errors.New("mnemosyned: session cannot be started, subject id is missing")
-				storage.On("Start", mock.AnythingOfType("string"), mock.AnythingOfType("map[string]string")).
+				storage.On("Start", mock.AnythingOfType("string"), mock.AnythingOfType("map[string]string"), ttl).
 					Return(session, expectedErr).
 					Once()
 			})
@@ -120,4 +142,366 @@ var _ = Describe("RPCServer", func() {
 			})
 		})
 	})
+	Describe("List", func() {
+		var (
+			req *mnemosyne.ListRequest
+			res *mnemosyne.ListResponse
+		)
+
+		JustBeforeEach(func() {
+			res, err = suite.service.List(context.Background(), req)
+		})
+		Context("with limit", func() {
+			BeforeEach(func() {
+				session = &mnemosyne.Session{Token: token, SubjectId: subjectID, Bag: bag, ExpireAt: protot.Now()}
+			})
+			Context("that is reached", func() {
+				BeforeEach(func() {
+					req = &mnemosyne.ListRequest{Limit: 1}
+					storage.On("List", "", int64(1), "", "", (*time.Time)(nil), (*time.Time)(nil)).
+						Return([]*mnemosyne.Session{session}, expectedErr).
+						Once()
+				})
+				It("should not return any error", func() {
+					Expect(err).ToNot(HaveOccurred())
+				})
+				It("should return next page token that points at last session", func() {
+					Expect(res.NextPageToken).To(Equal(newCursor(session).encode()))
+				})
+			})
+			Context("that is not reached", func() {
+				BeforeEach(func() {
+					req = &mnemosyne.ListRequest{Limit: 2, PageToken: newCursor(session).encode()}
+					storage.On("List", req.PageToken, int64(2), "", "", (*time.Time)(nil), (*time.Time)(nil)).
+						Return([]*mnemosyne.Session{session}, expectedErr).
+						Once()
+				})
+				It("should not return any error", func() {
+					Expect(err).ToNot(HaveOccurred())
+				})
+				It("should return empty next page token", func() {
+					Expect(res.NextPageToken).To(BeEmpty())
+				})
+			})
+		})
+		Context("with offset", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.ListRequest{Offset: 10, Limit: 10}
+			})
+			It("should return grpc error with code 3", func() {
+				AssertGRPCError(err, codes.InvalidArgument, "mnemosyne: offset is not supported, page token should be used instead")
+			})
+		})
+		Context("with malformed page token", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.ListRequest{Limit: 10, PageToken: "malformed"}
+				storage.On("List", req.PageToken, int64(10), "", "", (*time.Time)(nil), (*time.Time)(nil)).
+					Return(nil, mnemosyne.ErrInvalidPageToken).
+					Once()
+			})
+			It("should return grpc error with code 3", func() {
+				AssertGRPCError(err, codes.InvalidArgument, grpc.ErrorDesc(mnemosyne.ErrInvalidPageToken))
+			})
+		})
+	})
+	Describe("Touch", func() {
+		var (
+			req *mnemosyne.TouchRequest
+			res *mnemosyne.TouchResponse
+		)
+
+		JustBeforeEach(func() {
+			res, err = suite.service.Touch(context.Background(), req)
+		})
+		Context("with token", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.TouchRequest{Token: token}
+				session = &mnemosyne.Session{Token: token, SubjectId: subjectID, Bag: bag, ExpireAt: protot.Now()}
+			})
+			Context("without storage error", func() {
+				BeforeEach(func() {
+					storage.On("Touch", mock.AnythingOfType("*mnemosyne.Token"), ttl).
+						Return(session, expectedErr).
+						Once()
+				})
+				It("should not return any error", func() {
+					Expect(err).ToNot(HaveOccurred())
+				})
+				It("should return session with same subject id", func() {
+					Expect(res.Session.SubjectId).To(Equal(subjectID))
+				})
+				It("should return session with expire at timestamp", func() {
+					AssertTimestamp(res.Session.ExpireAt)
+				})
+				It("should return session with token", func() {
+					AssertToken(res.Session.Token)
+				})
+			})
+			Context("with session not found error", func() {
+				BeforeEach(func() {
+					storage.On("Touch", mock.AnythingOfType("*mnemosyne.Token"), ttl).
+						Return(nil, errSessionNotFound).
+						Once()
+				})
+				It("should return grpc error with code 5", func() {
+					AssertGRPCError(err, codes.NotFound, grpc.ErrorDesc(mnemosyne.ErrSessionNotFound))
+				})
+				It("should return an nil response", func() {
+					Expect(res).To(BeNil())
+				})
+			})
+		})
+		Context("with token of session started with lifetime longer than default", func() {
+			BeforeEach(func() {
+				storage.On("Start", subjectID, mock.AnythingOfType("map[string]string"), ttlMax).
+					Return(&mnemosyne.Session{Token: token, SubjectId: subjectID, ExpireAt: protot.TimeToTimestamp(time.Now().Add(ttlMax))}, nil).
+					Once()
+				started, err := suite.service.Start(context.Background(), &mnemosyne.StartRequest{SubjectId: subjectID, Ttl: int64(ttlMax / time.Second)})
+				Expect(err).ToNot(HaveOccurred())
+
+				req = &mnemosyne.TouchRequest{Token: started.Session.Token, Ttl: int64(ttlMax / time.Second)}
+				storage.On("Touch", mock.AnythingOfType("*mnemosyne.Token"), ttlMax).
+					Return(&mnemosyne.Session{Token: token, SubjectId: subjectID, ExpireAt: protot.TimeToTimestamp(time.Now().Add(ttlMax))}, nil).
+					Once()
+			})
+			It("should not return any error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+			It("should not shorten session lifetime", func() {
+				Expect(res.Session.ExpireAt.Time()).To(BeTemporally(">", time.Now().Add(ttl)))
+			})
+		})
+		Context("with ttl longer than maximum", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.TouchRequest{Token: token, Ttl: int64(ttlMax/time.Second) + 1}
+			})
+			It("should return grpc error with code 3", func() {
+				AssertGRPCError(err, codes.InvalidArgument, "mnemosyne: session ttl cannot be longer than "+ttlMax.String())
+			})
+		})
+		Context("without token", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.TouchRequest{}
+			})
+			It("should return grpc error with code 3", func() {
+				AssertGRPCError(err, codes.InvalidArgument, grpc.ErrorDesc(mnemosyne.ErrMissingToken))
+			})
+			It("should return an nil response", func() {
+				Expect(res).To(BeNil())
+			})
+		})
+	})
+	Describe("AbandonAll", func() {
+		var (
+			req *mnemosyne.AbandonAllRequest
+			res *mnemosyne.AbandonAllResponse
+		)
+
+		JustBeforeEach(func() {
+			res, err = suite.service.AbandonAll(context.Background(), req)
+		})
+		Context("with subject id and except token", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.AbandonAllRequest{SubjectId: subjectID, Except: token}
+				storage.On("AbandonAll", subjectID, mock.AnythingOfType("*mnemosyne.Token")).
+					Return([]*mnemosyne.Session{{}, {}, {}}, expectedErr).
+					Once()
+			})
+			It("should not return any error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+			It("should return number of abandoned sessions", func() {
+				Expect(res.Count).To(Equal(int64(3)))
+			})
+		})
+		Context("without subject id", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.AbandonAllRequest{}
+			})
+			It("should return grpc error with code 3", func() {
+				AssertGRPCError(err, codes.InvalidArgument, grpc.ErrorDesc(mnemosyne.ErrMissingSubjectID))
+			})
+			It("should return an nil response", func() {
+				Expect(res).To(BeNil())
+			})
+		})
+	})
+	Describe("DeleteValue", func() {
+		var (
+			req *mnemosyne.DeleteValueRequest
+			res *mnemosyne.DeleteValueResponse
+		)
+
+		JustBeforeEach(func() {
+			res, err = suite.service.DeleteValue(context.Background(), req)
+		})
+		Context("with token and key", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.DeleteValueRequest{Token: token, Key: "key"}
+				session = &mnemosyne.Session{Token: token, SubjectId: subjectID, Bag: map[string]string{}, ExpireAt: protot.Now()}
+				storage.On("DeleteValue", mock.AnythingOfType("*mnemosyne.Token"), "key").
+					Return(session, expectedErr).
+					Once()
+			})
+			It("should not return any error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+			It("should return session without deleted key", func() {
+				Expect(res.Session.Bag).ToNot(HaveKey("key"))
+			})
+		})
+		Context("without key", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.DeleteValueRequest{Token: token}
+			})
+			It("should return grpc error with code 3", func() {
+				AssertGRPCError(err, codes.InvalidArgument, "mnemosyne: missing bag key")
+			})
+			It("should return an nil response", func() {
+				Expect(res).To(BeNil())
+			})
+		})
+	})
+	Describe("Delete", func() {
+		var (
+			req *mnemosyne.DeleteRequest
+			res *mnemosyne.DeleteResponse
+		)
+
+		JustBeforeEach(func() {
+			res, err = suite.service.Delete(context.Background(), req)
+		})
+		Context("with token only", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.DeleteRequest{Token: token}
+				session = &mnemosyne.Session{Token: token, SubjectId: subjectID, Bag: bag, ExpireAt: protot.Now()}
+				storage.On("Delete", token, (*time.Time)(nil), (*time.Time)(nil)).
+					Return([]*mnemosyne.Session{session}, expectedErr).
+					Once()
+			})
+			It("should not return any error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+			It("should return number of deleted sessions", func() {
+				Expect(res.Count).To(Equal(int64(1)))
+			})
+		})
+		Context("with upper bound only", func() {
+			var expireAtTo time.Time
+
+			BeforeEach(func() {
+				expireAtTo = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+				req = &mnemosyne.DeleteRequest{ExpireAtTo: protot.TimeToTimestamp(expireAtTo)}
+				storage.On("Delete", (*mnemosyne.Token)(nil), (*time.Time)(nil), mock.MatchedBy(func(eat *time.Time) bool {
+					return eat != nil && eat.Equal(expireAtTo)
+				})).
+					Return([]*mnemosyne.Session{}, expectedErr).
+					Once()
+			})
+			It("should not return any error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+			It("should return zero count", func() {
+				Expect(res.Count).To(Equal(int64(0)))
+			})
+		})
+	})
+	Describe("Clear", func() {
+		var (
+			req *mnemosyne.ClearRequest
+			res *mnemosyne.ClearResponse
+		)
+
+		JustBeforeEach(func() {
+			res, err = suite.service.Clear(context.Background(), req)
+		})
+		Context("with token", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.ClearRequest{Token: token}
+				session = &mnemosyne.Session{Token: token, SubjectId: subjectID, Bag: map[string]string{}, ExpireAt: protot.Now()}
+				storage.On("Clear", mock.AnythingOfType("*mnemosyne.Token")).
+					Return(session, expectedErr).
+					Once()
+			})
+			It("should not return any error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+			It("should return not nil response", func() {
+				Expect(res).ToNot(BeNil())
+			})
+		})
+		Context("without token", func() {
+			BeforeEach(func() {
+				req = &mnemosyne.ClearRequest{}
+			})
+			It("should return grpc error with code 3", func() {
+				AssertGRPCError(err, codes.InvalidArgument, grpc.ErrorDesc(mnemosyne.ErrMissingToken))
+			})
+			It("should return an nil response", func() {
+				Expect(res).To(BeNil())
+			})
+		})
+	})
+	Describe("Watch", func() {
+		var (
+			stream mnemosyne.RPC_WatchClient
+			cancel context.CancelFunc
+			event  *mnemosyne.Event
+		)
+
+		BeforeEach(func() {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+
+			stream, err = suite.service.Watch(ctx, &mnemosyne.WatchRequest{SubjectId: subjectID})
+			Expect(err).ToNot(HaveOccurred())
+
+			events := suite.serviceServer.(*rpcServer).opts.events
+			Eventually(func() int {
+				events.RLock()
+				defer events.RUnlock()
+
+				return len(events.watchers)
+			}).Should(Equal(1))
+
+			for _, sid := range []string{"other_subject_id", subjectID} {
+				storage.On("Clear", mock.AnythingOfType("*mnemosyne.Token")).
+					Return(&mnemosyne.Session{Token: token, SubjectId: sid, ExpireAt: protot.Now()}, nil).
+					Once()
+				_, err = suite.service.Clear(context.Background(), &mnemosyne.ClearRequest{Token: token})
+				Expect(err).ToNot(HaveOccurred())
+			}
+		})
+		AfterEach(func() {
+			cancel()
+		})
+		JustBeforeEach(func() {
+			event, err = stream.Recv()
+		})
+		It("should not return any error", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should return update event of session that belongs to given subject", func() {
+			Expect(event.Type).To(Equal(mnemosyne.Event_UPDATED))
+			Expect(event.Session.SubjectId).To(Equal(subjectID))
+		})
+		Context("when disabled but events are consumed by the daemon itself", func() {
+			It("should return unimplemented error", func() {
+				rs := *suite.serviceServer.(*rpcServer)
+				rs.opts.watch = false
+
+				err := rs.Watch(&mnemosyne.WatchRequest{}, &watchServerStub{ctx: context.Background()})
+				AssertGRPCError(err, codes.Unimplemented, "mnemosyne: session events are not enabled")
+			})
+		})
+	})
 })
+
+type watchServerStub struct {
+	mnemosyne.RPC_WatchServer
+	ctx context.Context
+}
+
+func (wss *watchServerStub) Context() context.Context {
+	return wss.ctx
+}
@@ -6,6 +6,7 @@ import (
 	stdlog "log"
 	"os"
 
+	"github.com/garyburd/redigo/redis"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics/prometheus"
 	"github.com/lib/pq"
@@ -18,6 +19,11 @@ const (
 	monitoringEnginePrometheus = "prometheus"
 )
 
+var (
+	// monitoringDurationBuckets are upper bounds (in microseconds) of latency histograms, from 100µs up to ~6s.
+	monitoringDurationBuckets = stdprometheus.ExponentialBuckets(100, 2.5, 12)
+)
+
 func initPrometheus(namespace, subsystem string, constLabels stdprometheus.Labels) func() (*monitoring, error) {
 	return func() (*monitoring, error) {
 		rpcRequests := prometheus.NewCounter(
@@ -38,6 +44,17 @@ func initPrometheus(namespace, subsystem string, constLabels stdprometheus.Label
 				Help:        "Total number of errors that happen during RPC calles.",
 				ConstLabels: constLabels,
 			},
+			monitoringRPCErrorLabels,
+		)
+		rpcDuration := prometheus.NewHistogram(
+			stdprometheus.HistogramOpts{
+				Namespace:   namespace,
+				Subsystem:   subsystem,
+				Name:        "rpc_request_duration_microseconds",
+				Help:        "The RPC request latencies in microseconds.",
+				ConstLabels: constLabels,
+				Buckets:     monitoringDurationBuckets,
+			},
 			monitoringRPCLabels,
 		)
 
@@ -61,15 +78,129 @@ func initPrometheus(namespace, subsystem string, constLabels stdprometheus.Label
 			},
 			monitoringPostgresLabels,
 		)
+		postgresDuration := prometheus.NewHistogram(
+			stdprometheus.HistogramOpts{
+				Namespace:   namespace,
+				Subsystem:   subsystem,
+				Name:        "postgres_query_duration_microseconds",
+				Help:        "The SQL query latencies in microseconds.",
+				ConstLabels: constLabels,
+				Buckets:     monitoringDurationBuckets,
+			},
+			monitoringPostgresLabels,
+		)
+
+		redisCommands := prometheus.NewCounter(
+			stdprometheus.CounterOpts{
+				Namespace:   namespace,
+				Subsystem:   subsystem,
+				Name:        "redis_commands_total",
+				Help:        "Total number of Redis commands made.",
+				ConstLabels: constLabels,
+			},
+			monitoringRedisLabels,
+		)
+		redisErrors := prometheus.NewCounter(
+			stdprometheus.CounterOpts{
+				Namespace:   namespace,
+				Subsystem:   subsystem,
+				Name:        "redis_errors_total",
+				Help:        "Total number of errors that happen during Redis commands.",
+				ConstLabels: constLabels,
+			},
+			monitoringRedisLabels,
+		)
+		redisDuration := prometheus.NewHistogram(
+			stdprometheus.HistogramOpts{
+				Namespace:   namespace,
+				Subsystem:   subsystem,
+				Name:        "redis_command_duration_microseconds",
+				Help:        "The Redis command latencies in microseconds.",
+				ConstLabels: constLabels,
+				Buckets:     monitoringDurationBuckets,
+			},
+			monitoringRedisLabels,
+		)
+
+		reaperPurged := prometheus.NewCounter(
+			stdprometheus.CounterOpts{
+				Namespace:   namespace,
+				Subsystem:   subsystem,
+				Name:        "reaper_purged_sessions_total",
+				Help:        "Total number of expired sessions removed by reaper.",
+				ConstLabels: constLabels,
+			},
+			nil,
+		)
+		reaperErrors := prometheus.NewCounter(
+			stdprometheus.CounterOpts{
+				Namespace:   namespace,
+				Subsystem:   subsystem,
+				Name:        "reaper_errors_total",
+				Help:        "Total number of errors that happen during expired sessions removal.",
+				ConstLabels: constLabels,
+			},
+			nil,
+		)
+
+		cacheHits := prometheus.NewCounter(
+			stdprometheus.CounterOpts{
+				Namespace:   namespace,
+				Subsystem:   subsystem,
+				Name:        "cache_hits_total",
+				Help:        "Total number of storage lookups served by the cache.",
+				ConstLabels: constLabels,
+			},
+			nil,
+		)
+		cacheMisses := prometheus.NewCounter(
+			stdprometheus.CounterOpts{
+				Namespace:   namespace,
+				Subsystem:   subsystem,
+				Name:        "cache_misses_total",
+				Help:        "Total number of storage lookups that missed the cache.",
+				ConstLabels: constLabels,
+			},
+			nil,
+		)
+
+		sessionsActive := prometheus.NewGauge(
+			stdprometheus.GaugeOpts{
+				Namespace:   namespace,
+				Subsystem:   subsystem,
+				Name:        "sessions_active",
+				Help:        "Number of sessions that did not expire yet, refreshed periodically.",
+				ConstLabels: constLabels,
+			},
+			nil,
+		)
 
 		return &monitoring{
 			rpc: monitoringRPC{
 				requests: rpcRequests,
 				errors:   rpcErrors,
+				duration: rpcDuration,
 			},
 			postgres: monitoringPostgres{
-				queries: postgresQueries,
-				errors:  postgresErrors,
+				queries:  postgresQueries,
+				errors:   postgresErrors,
+				duration: postgresDuration,
+			},
+			redis: monitoringRedis{
+				commands: redisCommands,
+				errors:   redisErrors,
+				duration: redisDuration,
+			},
+			reaper: monitoringReaper{
+				purged: reaperPurged,
+				errors: reaperErrors,
+			},
+			cache: monitoringCache{
+				hits:   cacheHits,
+				misses: cacheMisses,
+			},
+			sessions: monitoringSessions{
+				active: sessionsActive,
 			},
 		}, nil
 	}
@@ -86,6 +217,14 @@ func initPostgres(connectionString string, logger log.Logger) *sql.DB {
 	return postgres
 }
 
+func initRedis(address, password string, database int, logger log.Logger) *redis.Pool {
+	pool := newRedisPool(address, password, database)
+
+	sklog.Info(logger, "redis connection pool initialized", "address", address, "database", database)
+
+	return pool
+}
+
 func initMonitoring(fn func() (*monitoring, error), logger log.Logger) *monitoring {
 	m, err := fn()
 	if err != nil {
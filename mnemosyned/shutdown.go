@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/piotrkowalczuk/sklog"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// shutdown stops both gateway and rpc api from accepting new calls and waits for in-flight ones to finish.
+// Watch streams never finish on their own, so watchers are disconnected first.
+// Calls that are still running once timeout passes are terminated by closing their connections.
+func shutdown(logger log.Logger, server *grpc.Server, gateway *http.Server, events *eventBus, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	events.close()
+
+	if gateway != nil {
+		if err := gateway.Shutdown(ctx); err != nil {
+			sklog.Error(logger, err)
+		}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		sklog.Info(logger, "in-flight calls did not finish in time, connections are being closed", "timeout", timeout)
+
+		server.Stop()
+		<-stopped
+	}
+}
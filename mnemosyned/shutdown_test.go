@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/piotrkowalczuk/mnemosyne"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestShutdown(t *testing.T) {
+	logger := log.NewNopLogger()
+	monitor := initMonitoring(initPrometheus("mnemosyne_test", "mnemosyne", stdprometheus.Labels{"server": "shutdown_test"}), logger)
+	storage := &storageMock{}
+	rs := newTestRPCServer(logger, storage, monitor)
+
+	serve := func(t *testing.T) (*grpc.Server, mnemosyne.RPCClient, func()) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		server := grpc.NewServer()
+		mnemosyne.RegisterRPCServer(server, rs)
+		go server.Serve(listener)
+
+		conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+		require.NoError(t, err)
+
+		return server, mnemosyne.NewRPCClient(conn), func() { conn.Close() }
+	}
+	finished := func(server *grpc.Server, timeout time.Duration) <-chan struct{} {
+		done := make(chan struct{})
+		go func() {
+			shutdown(logger, server, nil, rs.opts.events, timeout)
+			close(done)
+		}()
+
+		return done
+	}
+
+	t.Run("watch", func(t *testing.T) {
+		server, client, closeConn := serve(t)
+		defer closeConn()
+
+		stream, err := client.Watch(context.Background(), &mnemosyne.WatchRequest{})
+		require.NoError(t, err)
+
+		// Wait until the stream is actually being served.
+		for {
+			rs.opts.events.RLock()
+			n := len(rs.opts.events.watchers)
+			rs.opts.events.RUnlock()
+			if n > 0 {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		select {
+		case <-finished(server, time.Minute):
+		case <-time.After(5 * time.Second):
+			t.Fatal("shutdown did not finish while watch stream was open")
+		}
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unavailable, grpc.Code(err))
+	})
+	t.Run("timeout", func(t *testing.T) {
+		server, client, closeConn := serve(t)
+		defer closeConn()
+
+		storage.On("Get", mock.AnythingOfType("*mnemosyne.Token")).
+			Return(&mnemosyne.Session{}, nil).
+			After(time.Minute).
+			Once()
+
+		called := make(chan error, 1)
+		go func() {
+			tk := mnemosyne.NewToken([]byte("key"), []byte("hash"))
+			_, err := client.Get(context.Background(), &mnemosyne.GetRequest{Token: &tk})
+			called <- err
+		}()
+		// Give the call time to reach the storage.
+		time.Sleep(100 * time.Millisecond)
+
+		select {
+		case <-finished(server, 100*time.Millisecond):
+		case <-time.After(5 * time.Second):
+			t.Fatal("shutdown did not finish after grace period")
+		}
+
+		assert.Error(t, <-called)
+	})
+}
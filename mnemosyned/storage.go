@@ -23,16 +23,22 @@ type Storage interface {
 	Setup() error
 	TearDown() error
 
-	Start(string, map[string]string) (*mnemosyne.Session, error)
-	Abandon(*mnemosyne.Token) (bool, error)
+	Start(string, map[string]string, time.Duration) (*mnemosyne.Session, error)
+	Abandon(*mnemosyne.Token) (*mnemosyne.Session, error)
+	AbandonAll(string, *mnemosyne.Token) ([]*mnemosyne.Session, error)
+	Touch(*mnemosyne.Token, time.Duration) (*mnemosyne.Session, error)
 	Get(*mnemosyne.Token) (*mnemosyne.Session, error)
-	List(int64, int64, *time.Time, *time.Time) ([]*mnemosyne.Session, error)
+	List(string, int64, string, string, *time.Time, *time.Time) ([]*mnemosyne.Session, error)
 	Exists(*mnemosyne.Token) (bool, error)
-	Delete(*mnemosyne.Token, *time.Time, *time.Time) (int64, error)
-
-	SetValue(*mnemosyne.Token, string, string) (map[string]string, error)
-	//	DeleteValue(*mnemosyne.Token, string) (*mnemosyne.Session, error)
-	//	Clear(*mnemosyne.Token) (*mnemosyne.Session, error)
+	// Delete and Purge are bulk operations, callers can rely only on token and subject id of returned sessions.
+	Delete(*mnemosyne.Token, *time.Time, *time.Time) ([]*mnemosyne.Session, error)
+	Purge(int64) ([]*mnemosyne.Session, error)
+	Count() (int64, error)
+	Ping() error
+
+	SetValue(*mnemosyne.Token, string, string) (*mnemosyne.Session, error)
+	DeleteValue(*mnemosyne.Token, string) (*mnemosyne.Session, error)
+	Clear(*mnemosyne.Token) (*mnemosyne.Session, error)
 }
 
 type storageMock struct {
@@ -40,8 +46,8 @@ type storageMock struct {
 }
 
 // Start implements Storage interface.
-func (sm *storageMock) Start(subjectID string, bag map[string]string) (*mnemosyne.Session, error) {
-	args := sm.Called(subjectID, bag)
+func (sm *storageMock) Start(subjectID string, bag map[string]string, ttl time.Duration) (*mnemosyne.Session, error) {
+	args := sm.Called(subjectID, bag, ttl)
 
 	ses, ok := args.Get(0).(*mnemosyne.Session)
 	if !ok {
@@ -51,10 +57,36 @@ func (sm *storageMock) Start(subjectID string, bag map[string]string) (*mnemosyn
 }
 
 // Ąbandon implements Storage interface.
-func (sm *storageMock) Abandon(token *mnemosyne.Token) (bool, error) {
+func (sm *storageMock) Abandon(token *mnemosyne.Token) (*mnemosyne.Session, error) {
 	args := sm.Called(token)
 
-	return args.Bool(0), args.Error(1)
+	ses, ok := args.Get(0).(*mnemosyne.Session)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return ses, args.Error(1)
+}
+
+// AbandonAll implements Storage interface.
+func (sm *storageMock) AbandonAll(subjectID string, except *mnemosyne.Token) ([]*mnemosyne.Session, error) {
+	args := sm.Called(subjectID, except)
+
+	ses, ok := args.Get(0).([]*mnemosyne.Session)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return ses, args.Error(1)
+}
+
+// Touch implements Storage interface.
+func (sm *storageMock) Touch(token *mnemosyne.Token, ttl time.Duration) (*mnemosyne.Session, error) {
+	args := sm.Called(token, ttl)
+
+	ses, ok := args.Get(0).(*mnemosyne.Session)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return ses, args.Error(1)
 }
 
 // Get implements Storage interface.
@@ -69,8 +101,8 @@ func (sm *storageMock) Get(token *mnemosyne.Token) (*mnemosyne.Session, error) {
 }
 
 // List implements Storage interface.
-func (sm *storageMock) List(offset, limit int64, expireAtFrom, expireAtTo *time.Time) ([]*mnemosyne.Session, error) {
-	args := sm.Called(offset, limit, expireAtFrom, expireAtTo)
+func (sm *storageMock) List(pageToken string, limit int64, subjectID, bagKey string, expireAtFrom, expireAtTo *time.Time) ([]*mnemosyne.Session, error) {
+	args := sm.Called(pageToken, limit, subjectID, bagKey, expireAtFrom, expireAtTo)
 
 	ses, ok := args.Get(0).([]*mnemosyne.Session)
 	if !ok {
@@ -87,17 +119,70 @@ func (sm *storageMock) Exists(token *mnemosyne.Token) (bool, error) {
 }
 
 // Delete implements Storage interface.
-func (sm *storageMock) Delete(token *mnemosyne.Token, expireAtFrom, expireAtTo *time.Time) (int64, error) {
+func (sm *storageMock) Delete(token *mnemosyne.Token, expireAtFrom, expireAtTo *time.Time) ([]*mnemosyne.Session, error) {
 	args := sm.Called(token, expireAtFrom, expireAtTo)
 
+	ses, ok := args.Get(0).([]*mnemosyne.Session)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return ses, args.Error(1)
+}
+
+// Purge implements Storage interface.
+func (sm *storageMock) Purge(limit int64) ([]*mnemosyne.Session, error) {
+	args := sm.Called(limit)
+
+	ses, ok := args.Get(0).([]*mnemosyne.Session)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return ses, args.Error(1)
+}
+
+// Count implements Storage interface.
+func (sm *storageMock) Count() (int64, error) {
+	args := sm.Called()
+
 	return args.Get(0).(int64), args.Error(1)
 }
 
+// Ping implements Storage interface.
+func (sm *storageMock) Ping() error {
+	return sm.Called().Error(0)
+}
+
 // SetValue implements Storage interface.
-func (sm *storageMock) SetValue(token *mnemosyne.Token, key, value string) (map[string]string, error) {
+func (sm *storageMock) SetValue(token *mnemosyne.Token, key, value string) (*mnemosyne.Session, error) {
 	args := sm.Called(token, key, value)
 
-	return args.Get(0).(map[string]string), args.Error(1)
+	ses, ok := args.Get(0).(*mnemosyne.Session)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return ses, args.Error(1)
+}
+
+// DeleteValue implements Storage interface.
+func (sm *storageMock) DeleteValue(token *mnemosyne.Token, key string) (*mnemosyne.Session, error) {
+	args := sm.Called(token, key)
+
+	ses, ok := args.Get(0).(*mnemosyne.Session)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return ses, args.Error(1)
+}
+
+// Clear implements Storage interface.
+func (sm *storageMock) Clear(token *mnemosyne.Token) (*mnemosyne.Session, error) {
+	args := sm.Called(token)
+
+	ses, ok := args.Get(0).(*mnemosyne.Session)
+	if !ok {
+		return nil, args.Error(1)
+	}
+	return ses, args.Error(1)
 }
 
 // Setup implements Storage
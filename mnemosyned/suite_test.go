@@ -30,11 +30,16 @@ var (
 
 var (
 	address = "127.0.0.1:12345"
+	ttl     = 30 * time.Minute
+	ttlMax  = 2 * time.Hour
 )
 
 func init() {
 	tk := mnemosyne.NewToken([]byte(""), []byte("fake"))
 	notExistsToken = &tk
+
+	// Logger is global, it cannot be replaced once any test is running as gRPC goroutines might be still using it.
+	grpclog.SetLogger(sklog.NewGRPCLogger(sklog.NewHumaneLogger(GinkgoWriter, sklog.DefaultHTTPFormatter)))
 }
 
 func TestPackage(t *testing.T) {
@@ -98,30 +103,50 @@ func newIntegrationSuite(store Storage) *integrationSuite {
 	monitor := initMonitoring(initPrometheus("mnemosyne_test", "mnemosyne", stdprometheus.Labels{"server": "test"}), logger)
 
 	return &integrationSuite{
-		logger: logger,
-		serviceServer: &rpcServer{
-			alloc: struct {
-				abandon  handlerFunc
-				context  handlerFunc
-				delete   handlerFunc
-				exists   handlerFunc
-				get      handlerFunc
-				list     handlerFunc
-				setValue handlerFunc
-				start    handlerFunc
-			}{
-				abandon:  newHandlerFunc("abandon"),
-				context:  newHandlerFunc("context"),
-				delete:   newHandlerFunc("delete"),
-				exists:   newHandlerFunc("exists"),
-				get:      newHandlerFunc("get"),
-				list:     newHandlerFunc("list"),
-				setValue: newHandlerFunc("set_value"),
-				start:    newHandlerFunc("start"),
-			},
-			logger:  logger,
-			storage: store,
-			monitor: monitor,
+		logger:        logger,
+		serviceServer: newTestRPCServer(logger, store, monitor),
+	}
+}
+
+func newTestRPCServer(logger log.Logger, store Storage, monitor *monitoring) *rpcServer {
+	return &rpcServer{
+		alloc: struct {
+			abandon     handlerFunc
+			abandonAll  handlerFunc
+			clear       handlerFunc
+			context     handlerFunc
+			delete      handlerFunc
+			deleteValue handlerFunc
+			exists      handlerFunc
+			get         handlerFunc
+			list        handlerFunc
+			setValue    handlerFunc
+			start       handlerFunc
+			touch       handlerFunc
+			watch       handlerFunc
+		}{
+			abandon:     newHandlerFunc("abandon"),
+			abandonAll:  newHandlerFunc("abandon_all"),
+			clear:       newHandlerFunc("clear"),
+			context:     newHandlerFunc("context"),
+			delete:      newHandlerFunc("delete"),
+			deleteValue: newHandlerFunc("delete_value"),
+			exists:      newHandlerFunc("exists"),
+			get:         newHandlerFunc("get"),
+			list:        newHandlerFunc("list"),
+			setValue:    newHandlerFunc("set_value"),
+			start:       newHandlerFunc("start"),
+			touch:       newHandlerFunc("touch"),
+			watch:       newHandlerFunc("watch"),
+		},
+		logger:  logger,
+		storage: store,
+		monitor: monitor,
+		opts: handlerOpts{
+			ttl:    ttl,
+			ttlMax: ttlMax,
+			events: newEventBus(10),
+			watch:  true,
 		},
 	}
 }
@@ -132,7 +157,6 @@ func (is *integrationSuite) serve(dialOpts ...grpc.DialOption) (err error) {
 		return
 	}
 
-	grpclog.SetLogger(sklog.NewGRPCLogger(is.logger))
 	var opts []grpc.ServerOption
 	is.server = grpc.NewServer(opts...)
 
@@ -173,19 +197,29 @@ func testStorage_Start(t *testing.T, s Storage) {
 	bag := map[string]string{
 		"username": "test",
 	}
-	session, err := s.Start(subjectID, bag)
+	session, err := s.Start(subjectID, bag, ttl)
 
 	if assert.NoError(t, err) {
 		assert.Len(t, session.Token.Hash, 128)
 		assert.Equal(t, subjectID, session.SubjectId)
 		assert.Equal(t, bag, session.Bag)
+		assert.WithinDuration(t, time.Now().Add(ttl), session.ExpireAt.Time(), time.Minute)
+	}
+
+	// Check for custom session lifetime
+	short, err := s.Start(subjectID, bag, time.Minute)
+	if assert.NoError(t, err) {
+		assert.WithinDuration(t, time.Now().Add(time.Minute), short.ExpireAt.Time(), 30*time.Second)
+
+		_, err = s.Abandon(short.Token)
+		assert.NoError(t, err)
 	}
 }
 
 func testStorage_Get(t *testing.T, s Storage) {
 	ses, err := s.Start("subjectID", map[string]string{
 		"username": "test",
-	})
+	}, ttl)
 	require.NoError(t, err)
 
 	// Check for existing Token
@@ -207,25 +241,101 @@ func testStorage_List(t *testing.T, s Storage) {
 	key := "index"
 
 	for i := 1; i <= nb; i++ {
-		_, err := s.Start("subjectID", map[string]string{key: strconv.FormatInt(int64(i), 10)})
+		_, err := s.Start("subjectID", map[string]string{key: strconv.FormatInt(int64(i), 10)}, ttl)
 		require.NoError(t, err)
 	}
 
-	sessions, err := s.List(2, int64(nb), nil, nil)
-	if assert.NoError(t, err) {
-		assert.Len(t, sessions, nb)
-		for i, s := range sessions {
-			assert.NotEmpty(t, s.Token)
-			assert.NotEmpty(t, s.ExpireAt)
-			assert.Equal(t, s.Bag[key], strconv.FormatInt(int64(i+1), 10))
+	// Walk through all pages, every session needs to be returned exactly once and in order.
+	var (
+		pageToken string
+		sessions  []*mnemosyne.Session
+	)
+	listed := make(map[string]struct{})
+	for {
+		page, err := s.List(pageToken, 3, "", "", nil, nil)
+		require.NoError(t, err)
+
+		for _, ses := range page {
+			_, ok := listed[ses.Token.Encode()]
+			require.False(t, ok, "session listed more than once")
+			listed[ses.Token.Encode()] = struct{}{}
+
+			if _, ok := ses.Bag[key]; ok {
+				sessions = append(sessions, ses)
+			}
+		}
+		if len(page) < 3 {
+			break
 		}
+		pageToken = newCursor(page[len(page)-1]).encode()
+	}
+
+	assert.Len(t, sessions, nb)
+	for i, s := range sessions {
+		assert.NotEmpty(t, s.Token)
+		assert.NotEmpty(t, s.ExpireAt)
+		assert.Equal(t, s.Bag[key], strconv.FormatInt(int64(i+1), 10))
+	}
+
+	_, err := s.List("malformed", int64(nb), "", "", nil, nil)
+	assert.Equal(t, mnemosyne.ErrInvalidPageToken, err)
+
+	// Check for subject filter
+	subjectID := "listSubjectID"
+	subjectSessions := make([]*mnemosyne.Session, 0, 2)
+	for i := 0; i < 2; i++ {
+		ses, err := s.Start(subjectID, nil, ttl)
+		require.NoError(t, err)
+
+		subjectSessions = append(subjectSessions, ses)
+	}
+
+	now := time.Now()
+	for _, expiredAtFrom := range []*time.Time{nil, &now} {
+		sessions, err = s.List("", int64(nb), subjectID, "", expiredAtFrom, nil)
+		if assert.NoError(t, err) {
+			assert.Len(t, sessions, len(subjectSessions))
+			for _, ses := range sessions {
+				assert.Equal(t, subjectID, ses.SubjectId)
+			}
+		}
+	}
+
+	// Check for bag key filter
+	tagged, err := s.Start(subjectID, map[string]string{"tag": "value"}, ttl)
+	require.NoError(t, err)
+	// Limit of one forces storage to skip preceding sessions that do not have the key.
+	sessions, err = s.List("", 1, subjectID, "tag", nil, nil)
+	if assert.NoError(t, err) && assert.Len(t, sessions, 1) {
+		assert.Equal(t, tagged.Token.Encode(), sessions[0].Token.Encode())
+	}
+	_, err = s.Abandon(tagged.Token)
+	require.NoError(t, err)
+
+	// Pagination needs to respect subject filter as well.
+	sessions, err = s.List("", 1, subjectID, "", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	sessions, err = s.List(newCursor(sessions[0]).encode(), int64(nb), subjectID, "", nil, nil)
+	if assert.NoError(t, err) {
+		assert.Len(t, sessions, 1)
+	}
+
+	for _, ses := range subjectSessions {
+		_, err = s.Abandon(ses.Token)
+		require.NoError(t, err)
+	}
+
+	sessions, err = s.List("", int64(nb), subjectID, "", nil, nil)
+	if assert.NoError(t, err) {
+		assert.Len(t, sessions, 0)
 	}
 }
 
 func testStorage_Exists(t *testing.T, s Storage) {
 	new, err := s.Start("subjectID", map[string]string{
 		"username": "test",
-	})
+	}, ttl)
 	require.NoError(t, err)
 
 	// Check for existing Token
@@ -243,49 +353,121 @@ func testStorage_Exists(t *testing.T, s Storage) {
 func testStorage_Abandon(t *testing.T, s Storage) {
 	new, err := s.Start("subjectID", map[string]string{
 		"username": "test",
-	})
+	}, ttl)
 	require.NoError(t, err)
 
 	// Check for existing Token
-	ok2, err2 := s.Abandon(new.Token)
-	assert.True(t, ok2)
+	abandoned, err2 := s.Abandon(new.Token)
 	require.NoError(t, err2)
+	assert.Equal(t, new.Token.Encode(), abandoned.Token.Encode())
+	assert.Equal(t, new.SubjectId, abandoned.SubjectId)
+	assert.Equal(t, new.Bag, abandoned.Bag)
 
 	// Check for already abondond session
-	ok3, err3 := s.Abandon(new.Token)
-	assert.False(t, ok3)
+	abandoned3, err3 := s.Abandon(new.Token)
+	assert.Nil(t, abandoned3)
 	assert.EqualError(t, err3, errSessionNotFound.Error())
 
 	// Check for session that never exists
-	ok4, err4 := s.Abandon(notExistsToken)
-	assert.False(t, ok4)
+	abandoned4, err4 := s.Abandon(notExistsToken)
+	assert.Nil(t, abandoned4)
 	assert.EqualError(t, err4, errSessionNotFound.Error())
 }
 
+func testStorage_AbandonAll(t *testing.T, s Storage) {
+	subjectID := "subjectID-abandon-all"
+	tokens := make([]*mnemosyne.Token, 0, 3)
+	for i := 0; i < 3; i++ {
+		ses, err := s.Start(subjectID, map[string]string{}, ttl)
+		require.NoError(t, err)
+		tokens = append(tokens, ses.Token)
+	}
+	other, err := s.Start("subjectID", map[string]string{}, ttl)
+	require.NoError(t, err)
+
+	abandoned, err := s.AbandonAll(subjectID, tokens[0])
+	require.NoError(t, err)
+	if assert.Len(t, abandoned, 2) {
+		for _, ses := range abandoned {
+			assert.Equal(t, subjectID, ses.SubjectId)
+			assert.NotEqual(t, tokens[0].Encode(), ses.Token.Encode())
+		}
+	}
+
+	for i, tkn := range tokens {
+		exists, err := s.Exists(tkn)
+		require.NoError(t, err)
+		assert.Equal(t, i == 0, exists, "unexpected existence of session %d", i)
+	}
+
+	// Sessions of other subjects cannot be affected.
+	exists, err := s.Exists(other.Token)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	abandoned, err = s.AbandonAll(subjectID, nil)
+	require.NoError(t, err)
+	assert.Len(t, abandoned, 1)
+
+	abandoned, err = s.AbandonAll("subjectID-not-exists", nil)
+	require.NoError(t, err)
+	assert.Len(t, abandoned, 0)
+
+	_, err = s.Abandon(other.Token)
+	require.NoError(t, err)
+}
+
+func testStorage_Touch(t *testing.T, s Storage) {
+	new, err := s.Start("subjectID", map[string]string{
+		"username": "test",
+	}, time.Minute)
+	require.NoError(t, err)
+
+	touched, err := s.Touch(new.Token, ttl)
+	require.NoError(t, err)
+
+	assert.Equal(t, new.Token, touched.Token)
+	assert.Equal(t, new.SubjectId, touched.SubjectId)
+	assert.Equal(t, new.Bag, touched.Bag)
+	assert.WithinDuration(t, time.Now().Add(ttl), touched.ExpireAt.Time(), time.Minute)
+
+	got, err := s.Get(new.Token)
+	require.NoError(t, err)
+	assert.Equal(t, touched.ExpireAt.Time().Unix(), got.ExpireAt.Time().Unix())
+
+	// Check for session that never exists
+	_, err = s.Touch(notExistsToken, ttl)
+	assert.EqualError(t, err, errSessionNotFound.Error())
+
+	_, err = s.Abandon(new.Token)
+	require.NoError(t, err)
+}
+
 func testStorage_SetValue(t *testing.T, s Storage) {
 	new, err := s.Start("subjectID", map[string]string{
 		"username": "test",
-	})
+	}, ttl)
 	require.NoError(t, err)
 
 	// Check for existing Token
 	got, err2 := s.SetValue(new.Token, "email", "fake@email.com")
 	require.NoError(t, err2)
-	assert.Equal(t, 2, len(got))
-	assert.Equal(t, "fake@email.com", got["email"])
-	assert.Equal(t, "test", got["username"])
+	assert.Equal(t, new.SubjectId, got.SubjectId)
+	assert.Equal(t, 2, len(got.Bag))
+	assert.Equal(t, "fake@email.com", got.Bag["email"])
+	assert.Equal(t, "test", got.Bag["username"])
 
 	// Check for overwritten field
-	bag2, err2 := s.SetValue(new.Token, "email", "morefakethanbefore@email.com")
+	got2, err2 := s.SetValue(new.Token, "email", "morefakethanbefore@email.com")
 	require.NoError(t, err2)
-	assert.Equal(t, 2, len(bag2))
-	assert.Equal(t, "morefakethanbefore@email.com", bag2["email"])
-	assert.Equal(t, "test", bag2["username"])
+	assert.Equal(t, 2, len(got2.Bag))
+	assert.Equal(t, "morefakethanbefore@email.com", got2.Bag["email"])
+	assert.Equal(t, "test", got2.Bag["username"])
 
 	// Check for non existing Token
-	bag3, err3 := s.SetValue(notExistsToken, "email", "fake@email.com")
+	got3, err3 := s.SetValue(notExistsToken, "email", "fake@email.com")
 	require.Error(t, err3, errSessionNotFound.Error())
-	assert.Nil(t, bag3)
+	assert.Nil(t, got3)
 
 	wg := sync.WaitGroup{}
 	// Check for concurent access
@@ -329,12 +511,68 @@ func testStorage_SetValue(t *testing.T, s Storage) {
 	}
 }
 
+func testStorage_DeleteValue(t *testing.T, s Storage) {
+	new, err := s.Start("subjectID", map[string]string{
+		"username": "test",
+		"email":    "fake@email.com",
+	}, ttl)
+	require.NoError(t, err)
+
+	// Check for existing key
+	got, err := s.DeleteValue(new.Token, "email")
+	require.NoError(t, err)
+	assert.Equal(t, new.Token, got.Token)
+	assert.Equal(t, map[string]string{"username": "test"}, got.Bag)
+
+	// Check for already deleted key
+	got, err = s.DeleteValue(new.Token, "email")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "test"}, got.Bag)
+
+	got2, err := s.Get(new.Token)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{"username": "test"}, got2.Bag)
+	}
+
+	// Check for non existing Token
+	_, err = s.DeleteValue(notExistsToken, "email")
+	assert.EqualError(t, err, errSessionNotFound.Error())
+
+	_, err = s.Abandon(new.Token)
+	require.NoError(t, err)
+}
+
+func testStorage_Clear(t *testing.T, s Storage) {
+	new, err := s.Start("subjectID", map[string]string{
+		"username": "test",
+		"email":    "fake@email.com",
+	}, ttl)
+	require.NoError(t, err)
+
+	got, err := s.Clear(new.Token)
+	require.NoError(t, err)
+	assert.Equal(t, new.Token, got.Token)
+	assert.Len(t, got.Bag, 0)
+
+	// Check if bag can be filled again
+	filled, err := s.SetValue(new.Token, "username", "test")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "test"}, filled.Bag)
+
+	// Check for non existing Token
+	_, err = s.Clear(notExistsToken)
+	assert.EqualError(t, err, errSessionNotFound.Error())
+
+	_, err = s.Abandon(new.Token)
+	require.NoError(t, err)
+}
+
 func testStorage_Delete(t *testing.T, s Storage) {
 	expiredAtTo := time.Now().Add(35 * time.Minute)
 
-	affected, err := s.Delete(nil, nil, &expiredAtTo)
+	deleted, err := s.Delete(nil, nil, &expiredAtTo)
 	if assert.NoError(t, err) {
-		assert.Equal(t, int64(14), affected)
+		assert.Len(t, deleted, 14)
 	}
 
 	data := []struct {
@@ -372,7 +610,7 @@ func testStorage_Delete(t *testing.T, s Storage) {
 
 DataLoop:
 	for _, args := range data {
-		new, err := s.Start("subjectID", nil)
+		new, err := s.Start("subjectID", nil, ttl)
 		require.NoError(t, err)
 
 		if !assert.NoError(t, err) {
@@ -398,16 +636,82 @@ DataLoop:
 			expiredAtTo = &eat
 		}
 
-		affected, err = s.Delete(id, expiredAtFrom, expiredAtTo)
+		deleted, err = s.Delete(id, expiredAtFrom, expiredAtTo)
 		if assert.NoError(t, err) {
-			if assert.Equal(t, int64(1), affected, "one session should be removed for id: %-5t, expiredAtFrom: %-5t, expiredAtTo: %-5t", args.id, args.expiredAtFrom, args.expiredAtTo) {
+			if assert.Len(t, deleted, 1, "one session should be removed for id: %-5t, expiredAtFrom: %-5t, expiredAtTo: %-5t", args.id, args.expiredAtFrom, args.expiredAtTo) {
 				t.Logf("as expected session can be deleted with arguments id: %-5t, expiredAtFrom: %-5t, expiredAtTo: %-5t", args.id, args.expiredAtFrom, args.expiredAtTo)
 			}
 		}
 
-		affected, err = s.Delete(id, expiredAtFrom, expiredAtTo)
+		deleted, err = s.Delete(id, expiredAtFrom, expiredAtTo)
 		if assert.NoError(t, err) {
-			assert.Equal(t, int64(0), affected)
+			assert.Len(t, deleted, 0)
 		}
 	}
 }
+
+func testStorage_Purge(t *testing.T, s Storage) {
+	expired := make([]*mnemosyne.Session, 0, 3)
+	for i := 0; i < 3; i++ {
+		ses, err := s.Start("subjectID", nil, -time.Minute)
+		require.NoError(t, err)
+
+		expired = append(expired, ses)
+	}
+	alive, err := s.Start("subjectID", nil, ttl)
+	require.NoError(t, err)
+
+	// Expired but not yet purged sessions should not be visible
+	_, err = s.Get(expired[0].Token)
+	assert.EqualError(t, err, errSessionNotFound.Error())
+	exists, err := s.Exists(expired[0].Token)
+	if assert.NoError(t, err) {
+		assert.False(t, exists)
+	}
+	_, err = s.Touch(expired[0].Token, ttl)
+	assert.EqualError(t, err, errSessionNotFound.Error())
+
+	for _, expected := range []int{2, 1, 0} {
+		purged, err := s.Purge(2)
+		if assert.NoError(t, err) {
+			assert.Len(t, purged, expected)
+		}
+		for _, ses := range purged {
+			assert.NotNil(t, ses.Token)
+			assert.Equal(t, "subjectID", ses.SubjectId)
+		}
+	}
+
+	exists, err = s.Exists(alive.Token)
+	if assert.NoError(t, err) {
+		assert.True(t, exists)
+	}
+
+	_, err = s.Abandon(alive.Token)
+	require.NoError(t, err)
+}
+
+func testStorage_Count(t *testing.T, s Storage) {
+	before, err := s.Count()
+	require.NoError(t, err)
+
+	expired, err := s.Start("subjectID", nil, -time.Minute)
+	require.NoError(t, err)
+	alive := make([]*mnemosyne.Session, 0, 2)
+	for i := 0; i < 2; i++ {
+		ses, err := s.Start("subjectID", nil, ttl)
+		require.NoError(t, err)
+
+		alive = append(alive, ses)
+	}
+
+	after, err := s.Count()
+	if assert.NoError(t, err) {
+		assert.Equal(t, before+2, after, "expired sessions should not be counted")
+	}
+
+	for _, ses := range append(alive, expired) {
+		_, err = s.Delete(ses.Token, nil, nil)
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/piotrkowalczuk/sklog"
+)
+
+// tlsReloader keeps server certificate and client certificate authority up to date with files on disk.
+// Files are checked periodically, if any of them changed, all of them are loaded again.
+// Until new set of files loads successfully, previous one is used.
+type tlsReloader struct {
+	sync.RWMutex
+	logger   log.Logger
+	certFile string
+	keyFile  string
+	// caFile if not empty, clients are required to present certificate signed by this authority.
+	caFile      string
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	// fingerprint describes state of the files (size and modification time) at the moment of the last load.
+	fingerprint string
+	done        chan struct{}
+}
+
+func newTLSReloader(certFile, keyFile, caFile string, logger log.Logger) (*tlsReloader, error) {
+	switch {
+	case certFile == "":
+		return nil, errors.New("mnemosyned: tls certificate file is missing")
+	case keyFile == "":
+		return nil, errors.New("mnemosyned: tls key file is missing")
+	}
+
+	tr := &tlsReloader{
+		logger:   log.NewContext(logger).With("cert_file", certFile, "key_file", keyFile, "ca_file", caFile),
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		done:     make(chan struct{}),
+	}
+	if err := tr.load(); err != nil {
+		return nil, err
+	}
+
+	return tr, nil
+}
+
+// config returns TLS configuration that always uses the most recently loaded files.
+// Protocols are advertised during application-layer protocol negotiation.
+func (tr *tlsReloader) config(protos ...string) *tls.Config {
+	get := func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		tr.RLock()
+		defer tr.RUnlock()
+
+		cfg := &tls.Config{
+			Certificates: []tls.Certificate{*tr.certificate},
+			NextProtos:   protos,
+			MinVersion:   tls.VersionTLS12,
+		}
+		if tr.clientCAs != nil {
+			cfg.ClientCAs = tr.clientCAs
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+
+		return cfg, nil
+	}
+
+	return &tls.Config{
+		GetConfigForClient: get,
+		NextProtos:         protos,
+		MinVersion:         tls.VersionTLS12,
+	}
+}
+
+// start runs reload loop in separate goroutine.
+func (tr *tlsReloader) start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := tr.reload(); err != nil {
+					sklog.Error(tr.logger, err)
+				}
+			case <-tr.done:
+				return
+			}
+		}
+	}()
+
+	sklog.Info(tr.logger, "tls reloader has been started", "interval", interval)
+}
+
+// stop terminates reload loop.
+func (tr *tlsReloader) stop() {
+	close(tr.done)
+}
+
+// reload loads files again if any of them was modified or replaced since last load.
+func (tr *tlsReloader) reload() error {
+	fingerprint, err := tr.stat()
+	if err != nil {
+		return err
+	}
+
+	tr.RLock()
+	changed := fingerprint != tr.fingerprint
+	tr.RUnlock()
+
+	if !changed {
+		return nil
+	}
+	if err := tr.load(); err != nil {
+		return err
+	}
+
+	sklog.Info(tr.logger, "tls certificate has been reloaded")
+
+	return nil
+}
+
+func (tr *tlsReloader) load() error {
+	fingerprint, err := tr.stat()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(tr.certFile, tr.keyFile)
+	if err != nil {
+		return fmt.Errorf("mnemosyned: tls key pair loading failure: %s", err.Error())
+	}
+
+	var clientCAs *x509.CertPool
+	if tr.caFile != "" {
+		pem, err := ioutil.ReadFile(tr.caFile)
+		if err != nil {
+			return fmt.Errorf("mnemosyned: tls certificate authority loading failure: %s", err.Error())
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.New("mnemosyned: tls certificate authority file does not contain any certificate")
+		}
+	}
+
+	tr.Lock()
+	defer tr.Unlock()
+
+	tr.certificate = &certificate
+	tr.clientCAs = clientCAs
+	tr.fingerprint = fingerprint
+
+	return nil
+}
+
+func (tr *tlsReloader) stat() (string, error) {
+	var fingerprint string
+	for _, file := range []string{tr.certFile, tr.keyFile, tr.caFile} {
+		if file == "" {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", fmt.Errorf("mnemosyned: tls file stat failure: %s", err.Error())
+		}
+
+		fingerprint += fmt.Sprintf("%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano())
+	}
+
+	return fingerprint, nil
+}
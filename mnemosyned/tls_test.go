@@ -0,0 +1,200 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+func newTestCertificate(t *testing.T, serial int64, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.certificate, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCertificate{certificate: certificate, key: key}
+}
+
+func (tc *testCertificate) write(t *testing.T, certFile, keyFile string) {
+	der, err := x509.MarshalECPrivateKey(tc.key)
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tc.certificate.Raw}), 0600))
+	if keyFile != "" {
+		require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600))
+	}
+}
+
+// connect returns client end of loopback tcp connection, handshake of the server end is performed in separate goroutine.
+func connect(t *testing.T, server *tls.Config) (net.Conn, <-chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			done <- err
+			return
+		}
+		defer conn.Close()
+
+		done <- tls.Server(conn, server).Handshake()
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+
+	return conn, done
+}
+
+// handshake performs tls handshake between given client and server configurations and returns certificate of the server.
+func handshake(t *testing.T, server *tls.Config, client *tls.Config) (*x509.Certificate, error) {
+	clientConn, done := connect(t, server)
+	defer clientConn.Close()
+
+	conn := tls.Client(clientConn, client)
+	err := conn.Handshake()
+	if err == nil {
+		// Client finishes before the server verifies its certificate.
+		err = <-done
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestTLSReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mnemosyned")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		certFile, keyFile, caFile = filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.pem")
+		clientCertFile            = filepath.Join(dir, "client.pem")
+		clientKeyFile             = filepath.Join(dir, "client.key")
+	)
+
+	ca := newTestCertificate(t, 1, nil)
+	ca.write(t, caFile, "")
+	newTestCertificate(t, 2, ca).write(t, certFile, keyFile)
+	client := newTestCertificate(t, 3, ca)
+	client.write(t, clientCertFile, clientKeyFile)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+	clientCertificate, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+	require.NoError(t, err)
+
+	tr, err := newTLSReloader(certFile, keyFile, caFile, log.NewNopLogger())
+	require.NoError(t, err)
+
+	_, err = handshake(t, tr.config(), &tls.Config{RootCAs: roots, ServerName: "localhost"})
+	assert.Error(t, err, "client without certificate should be rejected")
+
+	got, err := handshake(t, tr.config(), &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: []tls.Certificate{clientCertificate}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), got.SerialNumber.Int64())
+
+	require.NoError(t, tr.reload())
+	got, err = handshake(t, tr.config(), &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: []tls.Certificate{clientCertificate}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), got.SerialNumber.Int64(), "certificate should not change if files did not")
+
+	newTestCertificate(t, 4, ca).write(t, certFile, keyFile)
+	require.NoError(t, tr.reload())
+	got, err = handshake(t, tr.config(), &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: []tls.Certificate{clientCertificate}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), got.SerialNumber.Int64(), "certificate should be reloaded")
+
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte("broken"), 0600))
+	assert.Error(t, tr.reload())
+	got, err = handshake(t, tr.config(), &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: []tls.Certificate{clientCertificate}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), got.SerialNumber.Int64(), "previous certificate should be used if reload fails")
+}
+
+func TestNewClientCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mnemosyned")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		certFile, keyFile, caFile = filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.pem")
+		clientCertFile            = filepath.Join(dir, "client.pem")
+		clientKeyFile             = filepath.Join(dir, "client.key")
+	)
+
+	ca := newTestCertificate(t, 1, nil)
+	ca.write(t, caFile, "")
+	newTestCertificate(t, 2, ca).write(t, certFile, keyFile)
+	newTestCertificate(t, 3, ca).write(t, clientCertFile, clientKeyFile)
+
+	tr, err := newTLSReloader(certFile, keyFile, caFile, log.NewNopLogger())
+	require.NoError(t, err)
+
+	creds, err := mnemosyne.NewClientCredentials(mnemosyne.TLSOpts{
+		CAFile:     caFile,
+		CertFile:   clientCertFile,
+		KeyFile:    clientKeyFile,
+		ServerName: "localhost",
+	})
+	require.NoError(t, err)
+
+	clientConn, done := connect(t, tr.config("h2"))
+	defer clientConn.Close()
+
+	_, _, err = creds.ClientHandshake(context.Background(), "localhost:8080", clientConn)
+	require.NoError(t, err)
+	require.NoError(t, <-done)
+
+	_, err = mnemosyne.NewClientCredentials(mnemosyne.TLSOpts{CertFile: clientCertFile})
+	assert.Error(t, err, "key file is missing")
+}
@@ -0,0 +1,156 @@
+// Package mnemosynehttp integrates mnemosyne sessions with net/http handlers.
+package mnemosynehttp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/piotrkowalczuk/mnemosyne"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// MiddlewareOpts configures how the session token is carried by HTTP requests and responses.
+type MiddlewareOpts struct {
+	// Header, if not empty, token is taken from this request header first.
+	Header string
+	// Cookie, if not empty, token is taken from and stored in cookie of given name.
+	Cookie string
+	// CookiePath, CookieDomain and CookieInsecure configure cookie set by Start.
+	// Cookie is HTTP only and secure unless CookieInsecure is true.
+	CookiePath     string
+	CookieDomain   string
+	CookieInsecure bool
+	// Load if true, session is retrieved and put into request context, it can be obtained by mnemosyne.SessionFromContext.
+	Load bool
+	// Required if true, request without valid session is passed to Unauthorized handler.
+	// Session is always loaded if it is required.
+	Required bool
+	// Unauthorized handles requests without valid session, it responds with 401 by default.
+	Unauthorized http.Handler
+}
+
+// Middleware extracts session token from incoming requests.
+type Middleware struct {
+	mnemosyne mnemosyne.Mnemosyne
+	opts      MiddlewareOpts
+}
+
+// NewMiddleware allocates new middleware instance.
+func NewMiddleware(m mnemosyne.Mnemosyne, opts MiddlewareOpts) *Middleware {
+	if opts.Unauthorized == nil {
+		opts.Unauthorized = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		})
+	}
+	if opts.CookiePath == "" {
+		opts.CookiePath = "/"
+	}
+
+	return &Middleware{
+		mnemosyne: m,
+		opts:      opts,
+	}
+}
+
+// Wrap returns handler that puts session token (see mnemosyne.TokenFromContext) and optionally session into request context.
+func (mw *Middleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		token, ok := mw.token(r)
+		if !ok {
+			if mw.opts.Required {
+				mw.opts.Unauthorized.ServeHTTP(rw, r)
+				return
+			}
+
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		ctx := mnemosyne.NewTokenContext(r.Context(), token)
+
+		if mw.opts.Load || mw.opts.Required {
+			ses, err := mw.mnemosyne.Get(ctx, token)
+			switch {
+			case err == nil:
+				ctx = mnemosyne.NewSessionContext(ctx, ses)
+			case grpc.Code(err) == codes.NotFound:
+				if mw.opts.Required {
+					mw.opts.Unauthorized.ServeHTTP(rw, r)
+					return
+				}
+			default:
+				http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		next.ServeHTTP(rw, r.WithContext(ctx))
+	})
+}
+
+// Start starts new session and sets the cookie, if configured.
+func (mw *Middleware) Start(rw http.ResponseWriter, r *http.Request, subjectID string, bag map[string]string) (*mnemosyne.Session, error) {
+	ses, err := mw.mnemosyne.Start(r.Context(), subjectID, bag)
+	if err != nil {
+		return nil, err
+	}
+
+	if mw.opts.Cookie != "" {
+		http.SetCookie(rw, mw.cookie(ses.Token.Encode(), ses.ExpireAt.Time()))
+	}
+
+	return ses, nil
+}
+
+// Abandon abandons session of given request and clears the cookie, if configured.
+// Cookie is cleared even if session does not exist anymore.
+func (mw *Middleware) Abandon(rw http.ResponseWriter, r *http.Request) error {
+	token, ok := mnemosyne.TokenFromContext(r.Context())
+	if !ok {
+		if token, ok = mw.token(r); !ok {
+			return mnemosyne.ErrMissingToken
+		}
+	}
+
+	if mw.opts.Cookie != "" {
+		c := mw.cookie("", time.Unix(0, 0))
+		c.MaxAge = -1
+		http.SetCookie(rw, c)
+	}
+
+	err := mw.mnemosyne.Abandon(r.Context(), token)
+	if grpc.Code(err) == codes.NotFound {
+		return nil
+	}
+
+	return err
+}
+
+func (mw *Middleware) token(r *http.Request) (mnemosyne.Token, bool) {
+	var value string
+	if mw.opts.Header != "" {
+		value = r.Header.Get(mw.opts.Header)
+	}
+	if value == "" && mw.opts.Cookie != "" {
+		if c, err := r.Cookie(mw.opts.Cookie); err == nil {
+			value = c.Value
+		}
+	}
+
+	token := mnemosyne.DecodeTokenString(value)
+
+	return token, !token.IsEmpty()
+}
+
+func (mw *Middleware) cookie(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     mw.opts.Cookie,
+		Value:    value,
+		Path:     mw.opts.CookiePath,
+		Domain:   mw.opts.CookieDomain,
+		Expires:  expires,
+		Secure:   !mw.opts.CookieInsecure,
+		HttpOnly: true,
+	}
+}
@@ -0,0 +1,153 @@
+package mnemosynehttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/piotrkowalczuk/mnemosyne"
+	"github.com/piotrkowalczuk/mnemosyne/mnemosynetest"
+	"github.com/piotrkowalczuk/protot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	token = mnemosyne.NewToken([]byte("0000000key"), []byte("hash"))
+)
+
+func TestMiddleware_Wrap(t *testing.T) {
+	session := &mnemosyne.Session{Token: &token, SubjectId: "subject"}
+
+	data := map[string]struct {
+		opts    MiddlewareOpts
+		prepare func(*http.Request, *mnemosynetest.Mnemosyne)
+		status  int
+		token   bool
+		session bool
+	}{
+		"without token": {
+			opts:   MiddlewareOpts{Header: "Authorization"},
+			status: http.StatusOK,
+		},
+		"without token, required": {
+			opts:   MiddlewareOpts{Header: "Authorization", Required: true},
+			status: http.StatusUnauthorized,
+		},
+		"header": {
+			opts: MiddlewareOpts{Header: "Authorization"},
+			prepare: func(r *http.Request, m *mnemosynetest.Mnemosyne) {
+				r.Header.Set("Authorization", token.Encode())
+			},
+			status: http.StatusOK,
+			token:  true,
+		},
+		"cookie, loaded": {
+			opts: MiddlewareOpts{Cookie: "sid", Load: true},
+			prepare: func(r *http.Request, m *mnemosynetest.Mnemosyne) {
+				r.AddCookie(&http.Cookie{Name: "sid", Value: token.Encode()})
+				m.On("Get", mock.Anything, token).Return(session, nil).Once()
+			},
+			status:  http.StatusOK,
+			token:   true,
+			session: true,
+		},
+		"session not found, loaded": {
+			opts: MiddlewareOpts{Header: "Authorization", Load: true},
+			prepare: func(r *http.Request, m *mnemosynetest.Mnemosyne) {
+				r.Header.Set("Authorization", token.Encode())
+				m.On("Get", mock.Anything, token).Return(nil, mnemosyne.ErrSessionNotFound).Once()
+			},
+			status: http.StatusOK,
+			token:  true,
+		},
+		"session not found, required": {
+			opts: MiddlewareOpts{Header: "Authorization", Required: true},
+			prepare: func(r *http.Request, m *mnemosynetest.Mnemosyne) {
+				r.Header.Set("Authorization", token.Encode())
+				m.On("Get", mock.Anything, token).Return(nil, mnemosyne.ErrSessionNotFound).Once()
+			},
+			status: http.StatusUnauthorized,
+		},
+		"storage failure": {
+			opts: MiddlewareOpts{Header: "Authorization", Load: true},
+			prepare: func(r *http.Request, m *mnemosynetest.Mnemosyne) {
+				r.Header.Set("Authorization", token.Encode())
+				m.On("Get", mock.Anything, token).Return(nil, errors.New("fake error")).Once()
+			},
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for hint, d := range data {
+		m := &mnemosynetest.Mnemosyne{}
+		r := httptest.NewRequest("GET", "/", nil)
+		if d.prepare != nil {
+			d.prepare(r, m)
+		}
+
+		var tokenFound, sessionFound bool
+		rec := httptest.NewRecorder()
+		NewMiddleware(m, d.opts).Wrap(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			var tkn mnemosyne.Token
+			tkn, tokenFound = mnemosyne.TokenFromContext(r.Context())
+			if tokenFound {
+				assert.Equal(t, token.Encode(), tkn.Encode(), hint)
+			}
+			_, sessionFound = mnemosyne.SessionFromContext(r.Context())
+		})).ServeHTTP(rec, r)
+
+		assert.Equal(t, d.status, rec.Code, hint)
+		assert.Equal(t, d.token, tokenFound, hint)
+		assert.Equal(t, d.session, sessionFound, hint)
+		m.AssertExpectations(t)
+	}
+}
+
+func TestMiddleware_Start(t *testing.T) {
+	expireAt := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	m := &mnemosynetest.Mnemosyne{}
+	m.On("Start", mock.Anything, "subject", map[string]string(nil)).
+		Return(&mnemosyne.Session{Token: &token, SubjectId: "subject", ExpireAt: protot.TimeToTimestamp(expireAt)}, nil).
+		Once()
+
+	rec := httptest.NewRecorder()
+	_, err := NewMiddleware(m, MiddlewareOpts{Cookie: "sid"}).Start(rec, httptest.NewRequest("POST", "/login", nil), "subject", nil)
+	require.NoError(t, err)
+
+	cookies := (&http.Response{Header: rec.Header()}).Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, "sid", cookies[0].Name)
+		assert.Equal(t, token.Encode(), cookies[0].Value)
+		assert.Equal(t, "/", cookies[0].Path)
+		assert.True(t, cookies[0].Secure)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.True(t, expireAt.Equal(cookies[0].Expires))
+	}
+	m.AssertExpectations(t)
+}
+
+func TestMiddleware_Abandon(t *testing.T) {
+	m := &mnemosynetest.Mnemosyne{}
+	m.On("Abandon", mock.Anything, token).Return(mnemosyne.ErrSessionNotFound).Once()
+
+	r := httptest.NewRequest("POST", "/logout", nil)
+	r.AddCookie(&http.Cookie{Name: "sid", Value: token.Encode()})
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, NewMiddleware(m, MiddlewareOpts{Cookie: "sid"}).Abandon(rec, r))
+
+	cookies := (&http.Response{Header: rec.Header()}).Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, "sid", cookies[0].Name)
+		assert.Equal(t, "", cookies[0].Value)
+		assert.Equal(t, -1, cookies[0].MaxAge)
+	}
+	m.AssertExpectations(t)
+
+	err := NewMiddleware(m, MiddlewareOpts{Cookie: "sid"}).Abandon(rec, httptest.NewRequest("POST", "/logout", nil))
+	assert.Equal(t, mnemosyne.ErrMissingToken, err)
+}
@@ -104,6 +104,29 @@ func (_m *Mnemosyne) Start(_a0 context.Context, _a1 string, _a2 map[string]strin
 	return r0, r1
 }
 
+// StartWithTTL provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *Mnemosyne) StartWithTTL(_a0 context.Context, _a1 string, _a2 time.Duration, _a3 map[string]string) (*mnemosyne.Session, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 *mnemosyne.Session
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration, map[string]string) *mnemosyne.Session); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration, map[string]string) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Abandon provides a mock function with given fields: _a0, _a1
 func (_m *Mnemosyne) Abandon(_a0 context.Context, _a1 mnemosyne.Token) error {
 	ret := _m.Called(_a0, _a1)
@@ -118,6 +141,73 @@ func (_m *Mnemosyne) Abandon(_a0 context.Context, _a1 mnemosyne.Token) error {
 	return r0
 }
 
+// AbandonAll provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Mnemosyne) AbandonAll(_a0 context.Context, _a1 string, _a2 *mnemosyne.Token) (int64, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, *mnemosyne.Token) int64); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *mnemosyne.Token) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Touch provides a mock function with given fields: _a0, _a1
+func (_m *Mnemosyne) Touch(_a0 context.Context, _a1 mnemosyne.Token) (*mnemosyne.Session, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *mnemosyne.Session
+	if rf, ok := ret.Get(0).(func(context.Context, mnemosyne.Token) *mnemosyne.Session); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, mnemosyne.Token) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TouchWithTTL provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Mnemosyne) TouchWithTTL(_a0 context.Context, _a1 mnemosyne.Token, _a2 time.Duration) (*mnemosyne.Session, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *mnemosyne.Session
+	if rf, ok := ret.Get(0).(func(context.Context, mnemosyne.Token, time.Duration) *mnemosyne.Session); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, mnemosyne.Token, time.Duration) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetValue provides a mock function with given fields: _a0, _a1, _a2, _a3
 func (_m *Mnemosyne) SetValue(_a0 context.Context, _a1 mnemosyne.Token, _a2 string, _a3 string) (map[string]string, error) {
 	ret := _m.Called(_a0, _a1, _a2, _a3)
@@ -141,6 +231,66 @@ func (_m *Mnemosyne) SetValue(_a0 context.Context, _a1 mnemosyne.Token, _a2 stri
 	return r0, r1
 }
 
+// DeleteValue provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Mnemosyne) DeleteValue(_a0 context.Context, _a1 mnemosyne.Token, _a2 string) (*mnemosyne.Session, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *mnemosyne.Session
+	if rf, ok := ret.Get(0).(func(context.Context, mnemosyne.Token, string) *mnemosyne.Session); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, mnemosyne.Token, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Clear provides a mock function with given fields: _a0, _a1
+func (_m *Mnemosyne) Clear(_a0 context.Context, _a1 mnemosyne.Token) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, mnemosyne.Token) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Watch provides a mock function with given fields: _a0, _a1
+func (_m *Mnemosyne) Watch(_a0 context.Context, _a1 string) (mnemosyne.RPC_WatchClient, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 mnemosyne.RPC_WatchClient
+	if rf, ok := ret.Get(0).(func(context.Context, string) mnemosyne.RPC_WatchClient); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(mnemosyne.RPC_WatchClient)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type RPCClient struct {
 	mock.Mock
 }
@@ -283,6 +433,52 @@ func (_m *RPCClient) Abandon(ctx context.Context, in *mnemosyne.AbandonRequest,
 	return r0, r1
 }
 
+// AbandonAll provides a mock function with given fields: ctx, in, opts
+func (_m *RPCClient) AbandonAll(ctx context.Context, in *mnemosyne.AbandonAllRequest, opts ...grpc.CallOption) (*mnemosyne.AbandonAllResponse, error) {
+	ret := _m.Called(ctx, in, opts)
+
+	var r0 *mnemosyne.AbandonAllResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *mnemosyne.AbandonAllRequest, ...grpc.CallOption) *mnemosyne.AbandonAllResponse); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.AbandonAllResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *mnemosyne.AbandonAllRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Touch provides a mock function with given fields: ctx, in, opts
+func (_m *RPCClient) Touch(ctx context.Context, in *mnemosyne.TouchRequest, opts ...grpc.CallOption) (*mnemosyne.TouchResponse, error) {
+	ret := _m.Called(ctx, in, opts)
+
+	var r0 *mnemosyne.TouchResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *mnemosyne.TouchRequest, ...grpc.CallOption) *mnemosyne.TouchResponse); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.TouchResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *mnemosyne.TouchRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetValue provides a mock function with given fields: ctx, in, opts
 func (_m *RPCClient) SetValue(ctx context.Context, in *mnemosyne.SetValueRequest, opts ...grpc.CallOption) (*mnemosyne.SetValueResponse, error) {
 	ret := _m.Called(ctx, in, opts)
@@ -306,6 +502,52 @@ func (_m *RPCClient) SetValue(ctx context.Context, in *mnemosyne.SetValueRequest
 	return r0, r1
 }
 
+// DeleteValue provides a mock function with given fields: ctx, in, opts
+func (_m *RPCClient) DeleteValue(ctx context.Context, in *mnemosyne.DeleteValueRequest, opts ...grpc.CallOption) (*mnemosyne.DeleteValueResponse, error) {
+	ret := _m.Called(ctx, in, opts)
+
+	var r0 *mnemosyne.DeleteValueResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *mnemosyne.DeleteValueRequest, ...grpc.CallOption) *mnemosyne.DeleteValueResponse); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.DeleteValueResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *mnemosyne.DeleteValueRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Clear provides a mock function with given fields: ctx, in, opts
+func (_m *RPCClient) Clear(ctx context.Context, in *mnemosyne.ClearRequest, opts ...grpc.CallOption) (*mnemosyne.ClearResponse, error) {
+	ret := _m.Called(ctx, in, opts)
+
+	var r0 *mnemosyne.ClearResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *mnemosyne.ClearRequest, ...grpc.CallOption) *mnemosyne.ClearResponse); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.ClearResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *mnemosyne.ClearRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, in, opts
 func (_m *RPCClient) Delete(ctx context.Context, in *mnemosyne.DeleteRequest, opts ...grpc.CallOption) (*mnemosyne.DeleteResponse, error) {
 	ret := _m.Called(ctx, in, opts)
@@ -329,6 +571,29 @@ func (_m *RPCClient) Delete(ctx context.Context, in *mnemosyne.DeleteRequest, op
 	return r0, r1
 }
 
+// Watch provides a mock function with given fields: ctx, in, opts
+func (_m *RPCClient) Watch(ctx context.Context, in *mnemosyne.WatchRequest, opts ...grpc.CallOption) (mnemosyne.RPC_WatchClient, error) {
+	ret := _m.Called(ctx, in, opts)
+
+	var r0 mnemosyne.RPC_WatchClient
+	if rf, ok := ret.Get(0).(func(context.Context, *mnemosyne.WatchRequest, ...grpc.CallOption) mnemosyne.RPC_WatchClient); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(mnemosyne.RPC_WatchClient)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *mnemosyne.WatchRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type RPCServer struct {
 	mock.Mock
 }
@@ -471,6 +736,52 @@ func (_m *RPCServer) Abandon(_a0 context.Context, _a1 *mnemosyne.AbandonRequest)
 	return r0, r1
 }
 
+// AbandonAll provides a mock function with given fields: _a0, _a1
+func (_m *RPCServer) AbandonAll(_a0 context.Context, _a1 *mnemosyne.AbandonAllRequest) (*mnemosyne.AbandonAllResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *mnemosyne.AbandonAllResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *mnemosyne.AbandonAllRequest) *mnemosyne.AbandonAllResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.AbandonAllResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *mnemosyne.AbandonAllRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Touch provides a mock function with given fields: _a0, _a1
+func (_m *RPCServer) Touch(_a0 context.Context, _a1 *mnemosyne.TouchRequest) (*mnemosyne.TouchResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *mnemosyne.TouchResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *mnemosyne.TouchRequest) *mnemosyne.TouchResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.TouchResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *mnemosyne.TouchRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetValue provides a mock function with given fields: _a0, _a1
 func (_m *RPCServer) SetValue(_a0 context.Context, _a1 *mnemosyne.SetValueRequest) (*mnemosyne.SetValueResponse, error) {
 	ret := _m.Called(_a0, _a1)
@@ -494,6 +805,52 @@ func (_m *RPCServer) SetValue(_a0 context.Context, _a1 *mnemosyne.SetValueReques
 	return r0, r1
 }
 
+// DeleteValue provides a mock function with given fields: _a0, _a1
+func (_m *RPCServer) DeleteValue(_a0 context.Context, _a1 *mnemosyne.DeleteValueRequest) (*mnemosyne.DeleteValueResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *mnemosyne.DeleteValueResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *mnemosyne.DeleteValueRequest) *mnemosyne.DeleteValueResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.DeleteValueResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *mnemosyne.DeleteValueRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Clear provides a mock function with given fields: _a0, _a1
+func (_m *RPCServer) Clear(_a0 context.Context, _a1 *mnemosyne.ClearRequest) (*mnemosyne.ClearResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *mnemosyne.ClearResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *mnemosyne.ClearRequest) *mnemosyne.ClearResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.ClearResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *mnemosyne.ClearRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: _a0, _a1
 func (_m *RPCServer) Delete(_a0 context.Context, _a1 *mnemosyne.DeleteRequest) (*mnemosyne.DeleteResponse, error) {
 	ret := _m.Called(_a0, _a1)
@@ -517,6 +874,20 @@ func (_m *RPCServer) Delete(_a0 context.Context, _a1 *mnemosyne.DeleteRequest) (
 	return r0, r1
 }
 
+// Watch provides a mock function with given fields: _a0, _a1
+func (_m *RPCServer) Watch(_a0 *mnemosyne.WatchRequest, _a1 mnemosyne.RPC_WatchServer) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*mnemosyne.WatchRequest, mnemosyne.RPC_WatchServer) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type Storage struct {
 	mock.Mock
 }
@@ -549,13 +920,13 @@ func (_m *Storage) TearDown() error {
 	return r0
 }
 
-// Start provides a mock function with given fields: _a0, _a1
-func (_m *Storage) Start(_a0 string, _a1 map[string]string) (*mnemosyne.Session, error) {
-	ret := _m.Called(_a0, _a1)
+// Start provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Storage) Start(_a0 string, _a1 map[string]string, _a2 time.Duration) (*mnemosyne.Session, error) {
+	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 *mnemosyne.Session
-	if rf, ok := ret.Get(0).(func(string, map[string]string) *mnemosyne.Session); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(string, map[string]string, time.Duration) *mnemosyne.Session); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*mnemosyne.Session)
@@ -563,8 +934,8 @@ func (_m *Storage) Start(_a0 string, _a1 map[string]string) (*mnemosyne.Session,
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, map[string]string) error); ok {
-		r1 = rf(_a0, _a1)
+	if rf, ok := ret.Get(1).(func(string, map[string]string, time.Duration) error); ok {
+		r1 = rf(_a0, _a1, _a2)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -573,14 +944,16 @@ func (_m *Storage) Start(_a0 string, _a1 map[string]string) (*mnemosyne.Session,
 }
 
 // Abandon provides a mock function with given fields: _a0
-func (_m *Storage) Abandon(_a0 *mnemosyne.Token) (bool, error) {
+func (_m *Storage) Abandon(_a0 *mnemosyne.Token) (*mnemosyne.Session, error) {
 	ret := _m.Called(_a0)
 
-	var r0 bool
-	if rf, ok := ret.Get(0).(func(*mnemosyne.Token) bool); ok {
+	var r0 *mnemosyne.Session
+	if rf, ok := ret.Get(0).(func(*mnemosyne.Token) *mnemosyne.Session); ok {
 		r0 = rf(_a0)
 	} else {
-		r0 = ret.Get(0).(bool)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.Session)
+		}
 	}
 
 	var r1 error
@@ -593,6 +966,52 @@ func (_m *Storage) Abandon(_a0 *mnemosyne.Token) (bool, error) {
 	return r0, r1
 }
 
+// AbandonAll provides a mock function with given fields: _a0, _a1
+func (_m *Storage) AbandonAll(_a0 string, _a1 *mnemosyne.Token) ([]*mnemosyne.Session, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*mnemosyne.Session
+	if rf, ok := ret.Get(0).(func(string, *mnemosyne.Token) []*mnemosyne.Session); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*mnemosyne.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, *mnemosyne.Token) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Touch provides a mock function with given fields: _a0, _a1
+func (_m *Storage) Touch(_a0 *mnemosyne.Token, _a1 time.Duration) (*mnemosyne.Session, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *mnemosyne.Session
+	if rf, ok := ret.Get(0).(func(*mnemosyne.Token, time.Duration) *mnemosyne.Session); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*mnemosyne.Token, time.Duration) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Get provides a mock function with given fields: _a0
 func (_m *Storage) Get(_a0 *mnemosyne.Token) (*mnemosyne.Session, error) {
 	ret := _m.Called(_a0)
@@ -616,13 +1035,13 @@ func (_m *Storage) Get(_a0 *mnemosyne.Token) (*mnemosyne.Session, error) {
 	return r0, r1
 }
 
-// List provides a mock function with given fields: _a0, _a1, _a2, _a3
-func (_m *Storage) List(_a0 int64, _a1 int64, _a2 *time.Time, _a3 *time.Time) ([]*mnemosyne.Session, error) {
-	ret := _m.Called(_a0, _a1, _a2, _a3)
+// List provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4, _a5
+func (_m *Storage) List(_a0 string, _a1 int64, _a2 string, _a3 string, _a4 *time.Time, _a5 *time.Time) ([]*mnemosyne.Session, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4, _a5)
 
 	var r0 []*mnemosyne.Session
-	if rf, ok := ret.Get(0).(func(int64, int64, *time.Time, *time.Time) []*mnemosyne.Session); ok {
-		r0 = rf(_a0, _a1, _a2, _a3)
+	if rf, ok := ret.Get(0).(func(string, int64, string, string, *time.Time, *time.Time) []*mnemosyne.Session); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4, _a5)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*mnemosyne.Session)
@@ -630,8 +1049,8 @@ func (_m *Storage) List(_a0 int64, _a1 int64, _a2 *time.Time, _a3 *time.Time) ([
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int64, int64, *time.Time, *time.Time) error); ok {
-		r1 = rf(_a0, _a1, _a2, _a3)
+	if rf, ok := ret.Get(1).(func(string, int64, string, string, *time.Time, *time.Time) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4, _a5)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -661,14 +1080,16 @@ func (_m *Storage) Exists(_a0 *mnemosyne.Token) (bool, error) {
 }
 
 // Delete provides a mock function with given fields: _a0, _a1, _a2
-func (_m *Storage) Delete(_a0 *mnemosyne.Token, _a1 *time.Time, _a2 *time.Time) (int64, error) {
+func (_m *Storage) Delete(_a0 *mnemosyne.Token, _a1 *time.Time, _a2 *time.Time) ([]*mnemosyne.Session, error) {
 	ret := _m.Called(_a0, _a1, _a2)
 
-	var r0 int64
-	if rf, ok := ret.Get(0).(func(*mnemosyne.Token, *time.Time, *time.Time) int64); ok {
+	var r0 []*mnemosyne.Session
+	if rf, ok := ret.Get(0).(func(*mnemosyne.Token, *time.Time, *time.Time) []*mnemosyne.Session); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
-		r0 = ret.Get(0).(int64)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*mnemosyne.Session)
+		}
 	}
 
 	var r1 error
@@ -681,16 +1102,74 @@ func (_m *Storage) Delete(_a0 *mnemosyne.Token, _a1 *time.Time, _a2 *time.Time)
 	return r0, r1
 }
 
+// Purge provides a mock function with given fields: _a0
+func (_m *Storage) Purge(_a0 int64) ([]*mnemosyne.Session, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []*mnemosyne.Session
+	if rf, ok := ret.Get(0).(func(int64) []*mnemosyne.Session); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*mnemosyne.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Count provides a mock function with given fields:
+func (_m *Storage) Count() (int64, error) {
+	ret := _m.Called()
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Ping provides a mock function with given fields:
+func (_m *Storage) Ping() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetValue provides a mock function with given fields: _a0, _a1, _a2
-func (_m *Storage) SetValue(_a0 *mnemosyne.Token, _a1 string, _a2 string) (map[string]string, error) {
+func (_m *Storage) SetValue(_a0 *mnemosyne.Token, _a1 string, _a2 string) (*mnemosyne.Session, error) {
 	ret := _m.Called(_a0, _a1, _a2)
 
-	var r0 map[string]string
-	if rf, ok := ret.Get(0).(func(*mnemosyne.Token, string, string) map[string]string); ok {
+	var r0 *mnemosyne.Session
+	if rf, ok := ret.Get(0).(func(*mnemosyne.Token, string, string) *mnemosyne.Session); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(map[string]string)
+			r0 = ret.Get(0).(*mnemosyne.Session)
 		}
 	}
 
@@ -704,6 +1183,52 @@ func (_m *Storage) SetValue(_a0 *mnemosyne.Token, _a1 string, _a2 string) (map[s
 	return r0, r1
 }
 
+// DeleteValue provides a mock function with given fields: _a0, _a1
+func (_m *Storage) DeleteValue(_a0 *mnemosyne.Token, _a1 string) (*mnemosyne.Session, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *mnemosyne.Session
+	if rf, ok := ret.Get(0).(func(*mnemosyne.Token, string) *mnemosyne.Session); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*mnemosyne.Token, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Clear provides a mock function with given fields: _a0
+func (_m *Storage) Clear(_a0 *mnemosyne.Token) (*mnemosyne.Session, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *mnemosyne.Session
+	if rf, ok := ret.Get(0).(func(*mnemosyne.Token) *mnemosyne.Session); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mnemosyne.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*mnemosyne.Token) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type RandomBytesGenerator struct {
 	mock.Mock
 }
@@ -0,0 +1,60 @@
+package mnemosyne
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// TLSOpts configures transport security of the connection to mnemosyned.
+type TLSOpts struct {
+	// CAFile if provided, server certificate is verified against this authority instead of system ones.
+	CAFile string
+	// CertFile and KeyFile if provided, client certificate is presented to the server (required by mutual TLS).
+	CertFile string
+	KeyFile  string
+	// ServerName if provided, overrides name used to verify server certificate.
+	ServerName string
+}
+
+// NewClientCredentials allocates transport credentials that can be used to establish connection passed to New:
+//
+//	creds, err := mnemosyne.NewClientCredentials(mnemosyne.TLSOpts{CAFile: "ca.pem"})
+//	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(creds))
+//	m := mnemosyne.New(conn, mnemosyne.MnemosyneOpts{})
+func NewClientCredentials(opts TLSOpts) (credentials.TransportCredentials, error) {
+	cfg := &tls.Config{
+		ServerName: opts.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if opts.CAFile != "" {
+		pem, err := ioutil.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("mnemosyne: tls certificate authority loading failure: %s", err.Error())
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("mnemosyne: tls certificate authority file does not contain any certificate")
+		}
+	}
+
+	switch {
+	case opts.CertFile != "" && opts.KeyFile != "":
+		certificate, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("mnemosyne: tls key pair loading failure: %s", err.Error())
+		}
+
+		cfg.Certificates = []tls.Certificate{certificate}
+	case opts.CertFile != "" || opts.KeyFile != "":
+		return nil, errors.New("mnemosyne: tls certificate and key files need to be provided together")
+	}
+
+	return credentials.NewTLS(cfg), nil
+}